@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"github.com/brittandeyoung/ckia/internal/client"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // AwsCmd represents the aws command
@@ -17,13 +19,39 @@ var AwsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(AwsCmd)
 
-	// Here you will define your flags and configuration settings.
+	// The aws flags can also be set in the aws section of the config file,
+	// e.g. aws.profile or aws.roleArn.
+	AwsCmd.PersistentFlags().String("profile", "", "The aws shared config profile to use. Default: the AWS_PROFILE environment variable or the default profile.")
+	AwsCmd.PersistentFlags().String("region", "", "The aws region to use. Default: the region of the aws profile or environment.")
+	AwsCmd.PersistentFlags().String("role-arn", "", "The arn of a role to assume with the configured credentials.")
+	AwsCmd.PersistentFlags().String("external-id", "", "An optional external id to use when assuming the role-arn role.")
+	AwsCmd.PersistentFlags().String("mfa-serial", "", "The serial number of the MFA device required to assume the role-arn role. The MFA token is read from stdin.")
+	AwsCmd.PersistentFlags().String("endpoint-url", "", "A url to send every aws api call to, e.g. http://localhost:4566 for LocalStack.")
+	for key, flag := range awsConfigKeys {
+		cobra.CheckErr(viper.BindPFlag(key, AwsCmd.PersistentFlags().Lookup(flag)))
+	}
+}
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// AwsCmd.PersistentFlags().String("foo", "", "A help for foo")
+// awsConfigKeys maps the keys of the config file to the aws flags that set
+// them.
+var awsConfigKeys = map[string]string{
+	"aws.profile":     "profile",
+	"aws.region":      "region",
+	"aws.roleArn":     "role-arn",
+	"aws.externalId":  "external-id",
+	"aws.mfaSerial":   "mfa-serial",
+	"aws.endpointUrl": "endpoint-url",
+}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// AwsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+// AwsConfigOptions returns the aws config options set by the aws flags or the
+// aws section of the config file.
+func AwsConfigOptions() client.ConfigOptions {
+	return client.ConfigOptions{
+		Profile:     viper.GetString("aws.profile"),
+		Region:      viper.GetString("aws.region"),
+		RoleArn:     viper.GetString("aws.roleArn"),
+		ExternalId:  viper.GetString("aws.externalId"),
+		MFASerial:   viper.GetString("aws.mfaSerial"),
+		EndpointURL: viper.GetString("aws.endpointUrl"),
+	}
 }
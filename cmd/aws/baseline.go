@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brittandeyoung/ckia/cmd"
+	"github.com/brittandeyoung/ckia/internal/suppression"
+	"github.com/spf13/cobra"
+)
+
+var baselineOutFile string
+
+// baselineCmd represents the baseline command
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Record the current findings for aws as a baseline",
+	Long: `Run the available checks for aws cloud and record every current finding in a baseline file.
+Passing the baseline file to the check command with the baseline flag only reports findings that are not in the baseline.`,
+	RunE: func(c *cobra.Command, args []string) error {
+		s, err := runChecks(context.Background())
+		if err != nil {
+			return err
+		}
+
+		// A check that failed to run would be missing from the baseline, so its
+		// findings would all be reported as new on the next run.
+		if failed := failedToRun(s.results); failed > 0 {
+			return cmd.CheckError(fmt.Errorf("%d check run(s) failed to run, baseline not recorded", failed))
+		}
+
+		baseline := suppression.NewBaseline(s.results, time.Now())
+		if err := baseline.Save(baselineOutFile); err != nil {
+			return err
+		}
+
+		fmt.Printf("Recorded %d finding(s) in baseline %s\n", len(baseline.Findings), baselineOutFile)
+		return nil
+	},
+}
+
+func init() {
+	cmd.AwsCmd.AddCommand(baselineCmd)
+	addScanFlags(baselineCmd)
+	baselineCmd.Flags().StringVarP(&baselineOutFile, "out-file", "o", "ckia-baseline.json", "A path to the file to store the baseline in.")
+}
@@ -5,129 +5,625 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/brittandeyoung/ckia/cmd"
 	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	_ "github.com/brittandeyoung/ckia/internal/aws/cost"
+	_ "github.com/brittandeyoung/ckia/internal/aws/faulttolerance"
+	_ "github.com/brittandeyoung/ckia/internal/aws/performance"
+	_ "github.com/brittandeyoung/ckia/internal/aws/security"
+	_ "github.com/brittandeyoung/ckia/internal/aws/servicelimits"
 	"github.com/brittandeyoung/ckia/internal/client"
 	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/history"
+	"github.com/brittandeyoung/ckia/internal/policy"
+	internalPricing "github.com/brittandeyoung/ckia/internal/pricing"
+	"github.com/brittandeyoung/ckia/internal/report"
+	"github.com/brittandeyoung/ckia/internal/scheduler"
+	internalSecurityHub "github.com/brittandeyoung/ckia/internal/securityhub"
+	"github.com/brittandeyoung/ckia/internal/suppression"
 	"github.com/k0kubun/go-ansi"
 	"github.com/schollz/progressbar/v3"
-	"github.com/smirzaei/parallel"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 type Checks struct {
-	CostOptimization []interface{} `json:"costOptimization"`
-	Performance      []interface{} `json:"performance"`
-	Security         []interface{} `json:"security"`
-	FaultTolerance   []interface{} `json:"faultTolerance"`
-	ServiceLimits    []interface{} `json:"serviceLimits"`
+	EstimatedMonthlySavings float64       `json:"estimatedMonthlySavings"`
+	CostOptimization        []interface{} `json:"costOptimization"`
+	Performance             []interface{} `json:"performance"`
+	Security                []interface{} `json:"security"`
+	FaultTolerance          []interface{} `json:"faultTolerance"`
+	ServiceLimits           []interface{} `json:"serviceLimits"`
 }
 
+// newChecks returns a Checks with every category initialized, so categories
+// without any results are still present in the output.
+func newChecks() Checks {
+	return Checks{
+		CostOptimization: []interface{}{},
+		Performance:      []interface{}{},
+		Security:         []interface{}{},
+		FaultTolerance:   []interface{}{},
+		ServiceLimits:    []interface{}{},
+	}
+}
+
+// categories maps each check category to the list its results are stored in.
+func (c *Checks) categories() map[string]*[]interface{} {
+	return map[string]*[]interface{}{
+		common.CategoryCostOptimization: &c.CostOptimization,
+		common.CategoryPerformance:      &c.Performance,
+		common.CategorySecurity:         &c.Security,
+		common.CategoryFaultTolerance:   &c.FaultTolerance,
+		common.CategoryServiceLimits:    &c.ServiceLimits,
+	}
+}
+
+// add appends a check result to the list for the category of the check that
+// produced it.
+func (c *Checks) add(category string, res interface{}) error {
+	results, ok := c.categories()[category]
+	if !ok {
+		return fmt.Errorf("unknown check category: %s", category)
+	}
+	*results = append(*results, res)
+	return nil
+}
+
+// selectedChecks returns the metadata of the registered checks filtered by the
+// include-checks and exclude-checks flags.
+func selectedChecks() []common.Check {
+	var checks []common.Check
+	for _, id := range internalAws.CheckIds() {
+		if !checkSelected(id) {
+			continue
+		}
+		check, _ := internalAws.NewCheck(id)
+		checks = append(checks, check.Metadata())
+	}
+	return checks
+}
+
+// excludedChecks returns the metadata of the registered checks that are not
+// run because of the include-checks and exclude-checks flags.
+func excludedChecks() []common.Check {
+	var checks []common.Check
+	for _, id := range internalAws.CheckIds() {
+		if checkSelected(id) {
+			continue
+		}
+		check, _ := internalAws.NewCheck(id)
+		checks = append(checks, check.Metadata())
+	}
+	return checks
+}
+
+func checkSelected(id string) bool {
+	return (len(includeChecks) == 0 || common.StringSliceContains(includeChecks, id)) && !common.StringSliceContains(excludeChecks, id)
+}
+
+// checkRun is a single execution of a check against one regional client.
+type checkRun struct {
+	check common.Check
+	conn  client.AWSClient
+}
+
+// planCheckRuns fans regional checks out across every client. Global checks
+// only run once per account using the first client for that account.
+func planCheckRuns(checks []common.Check, conns []client.AWSClient) []checkRun {
+	var runs []checkRun
+	for _, check := range checks {
+		var accountsRun []string
+		for _, conn := range conns {
+			if check.Global {
+				if common.StringSliceContains(accountsRun, conn.AccountId) {
+					continue
+				}
+				accountsRun = append(accountsRun, conn.AccountId)
+			}
+			runs = append(runs, checkRun{check: check, conn: conn})
+		}
+	}
+	return runs
+}
+
+// scanTarget is an account to scan and the aws config used to access it.
+type scanTarget struct {
+	account client.Account
+	cfg     aws.Config
+}
+
+// resolveTargets returns the account of the configured credentials and the
+// accounts to scan. Without the organization flag only the account of the
+// configured credentials is scanned. With it, every active account in the
+// organization is scanned by assuming the org-role-name role in each member
+// account.
+func resolveTargets(ctx context.Context, cfg aws.Config, conn client.AWSClient) (client.Account, []scanTarget, error) {
+	identity, err := conn.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return client.Account{}, nil, err
+	}
+	callerAccountId := aws.ToString(identity.Account)
+	callerArn, err := arn.Parse(aws.ToString(identity.Arn))
+	if err != nil {
+		return client.Account{}, nil, err
+	}
+	caller := client.Account{Id: callerAccountId, Partition: callerArn.Partition}
+
+	if !organization {
+		return caller, []scanTarget{{account: caller, cfg: cfg}}, nil
+	}
+
+	accounts, err := client.OrganizationAccounts(ctx, conn)
+	if err != nil {
+		return caller, nil, fmt.Errorf("unable to list organization accounts: %w", err)
+	}
+
+	var targets []scanTarget
+	for _, account := range accounts {
+		account.Partition = callerArn.Partition
+		if account.Id == callerAccountId {
+			targets = append(targets, scanTarget{account: account, cfg: cfg})
+			continue
+		}
+		roleArn := client.RoleArn(callerArn.Partition, account.Id, orgRoleName)
+		targets = append(targets, scanTarget{account: account, cfg: client.AssumeRoleConfig(cfg, roleArn, orgExternalId)})
+	}
+	return caller, targets, nil
+}
+
+// resolveRegions expands the regions flag into the list of regions to scan.
+// When no regions are provided, the region from the aws config is used.
+func resolveRegions(ctx context.Context, conn client.AWSClient) ([]string, error) {
+	if len(regions) == 0 {
+		if conn.Region == "" {
+			return nil, errors.New("no region configured, set AWS_REGION or use the region or regions flag")
+		}
+		return []string{conn.Region}, nil
+	}
+	if common.StringSliceContains(regions, allRegions) {
+		return client.EnabledRegions(ctx, conn)
+	}
+
+	var resolved []string
+	for _, region := range regions {
+		if !common.StringSliceContains(resolved, region) {
+			resolved = append(resolved, region)
+		}
+	}
+	return resolved, nil
+}
+
+// scan is the outcome of running the selected checks.
+type scan struct {
+	checks  Checks
+	results []common.Result
+	// cfg and caller are the aws config and account of the configured
+	// credentials the checks were run with.
+	cfg    aws.Config
+	caller client.Account
+	// targets are the accounts the checks were run against.
+	targets []scanTarget
+}
+
+// runChecks runs the selected checks against every account and region in scope
+// and returns the results grouped by category along with every result. Findings
+// matched by the suppressions or baseline flags are moved to the suppressed
+// findings of their result.
+func runChecks(ctx context.Context) (*scan, error) {
+	cfg, err := client.LoadConfig(ctx, cmd.AwsConfigOptions())
+	if err != nil {
+		return nil, cmd.UsageError(err)
+	}
+	checkParams, err := internalAws.CheckParameters(viper.GetStringMap("checks"))
+	if err != nil {
+		return nil, cmd.UsageError(err)
+	}
+	suppressor, err := loadSuppressor()
+	if err != nil {
+		return nil, cmd.UsageError(err)
+	}
+	tagFilter, err := client.ParseTagFilter(tagFilters, excludeTags)
+	if err != nil {
+		return nil, cmd.UsageError(err)
+	}
+	client.SetRateLimit(apiRateLimit)
+	conn := client.InitiateClient(cfg)
+	if pricingCache == "" {
+		pricingCache, err = internalPricing.DefaultCachePath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	cache, err := internalPricing.LoadCache(pricingCache)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load pricing cache: %w", err)
+	}
+	estimator := internalPricing.NewEstimator(conn.Pricing, cache)
+	caller, targets, err := resolveTargets(ctx, cfg, conn)
+	if err != nil {
+		return nil, err
+	}
+	var conns []client.AWSClient
+	for _, target := range targets {
+		scanRegions, err := resolveRegions(ctx, client.InitiateClient(target.cfg))
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve regions for account (%s): %w", target.account.Id, err)
+		}
+		conns = append(conns, client.InitiateClients(target.cfg, target.account, scanRegions)...)
+	}
+	for i := range conns {
+		conns[i].Estimator = estimator
+		conns[i].TagFilter = tagFilter
+	}
+	allChecks := newChecks()
+	var results []common.Result
+	runs := planCheckRuns(selectedChecks(), conns)
+	// Progress is written to stderr, so stdout only holds the check results and
+	// can be redirected to a file in any out-format.
+	bar := progressbar.NewOptions(len(runs),
+		progressbar.OptionSetWriter(ansi.NewAnsiStderr()),
+		progressbar.OptionOnCompletion(func() {
+			fmt.Fprintln(os.Stderr)
+		}),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionFullWidth(),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowElapsedTimeOnFinish(),
+		progressbar.OptionSetDescription(fmt.Sprintf("Running [cyan][%d][reset] ckia Checks across [cyan][%d][reset] account(s)...", len(runs), len(targets))),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}))
+	var mu sync.Mutex
+	tasks := make([]scheduler.Task, len(runs))
+	// The API calls of each check run are counted, so checks that are heavy on
+	// the API or throttled can be found in the results.
+	counters := make([]*client.APIUsageCounter, len(runs))
+	for i, run := range runs {
+		i, run := i, run
+		tasks[i] = func(ctx context.Context) error {
+			defer bar.Add(1)
+			ctx, counters[i] = client.WithAPIUsageCounter(ctx)
+			check, _ := internalAws.NewCheck(run.check.Id)
+			res, err := check.Run(ctx, run.conn, checkParams[run.check.Id])
+			if err != nil || res == nil {
+				return err
+			}
+			res.SetAPIUsage(counters[i].Usage())
+			mu.Lock()
+			defer mu.Unlock()
+			suppressor.Apply(res)
+			results = append(results, res)
+			allChecks.EstimatedMonthlySavings = common.RoundCents(allChecks.EstimatedMonthlySavings + res.Summary().EstimatedMonthlySavings)
+			return allChecks.add(run.check.Category, res)
+		}
+	}
+
+	// A check run that fails is reported as failed_to_run, so the results of
+	// every other check run are still reported.
+	for i, err := range scheduler.Run(ctx, tasks, scheduler.Options{Workers: workers, Timeout: checkTimeout}) {
+		if err == nil {
+			continue
+		}
+		run := runs[i]
+		region := run.conn.Region
+		if run.check.Global {
+			region = ""
+		}
+		fmt.Fprintf(os.Stderr, "Warning: check (%s) failed in account (%s) region (%s): %s\n", run.check.Id, run.conn.AccountId, region, err)
+		res := common.NewFailedResult(run.check, run.conn.AccountId, region, checkParams[run.check.Id], err)
+		if counters[i] != nil {
+			res.SetAPIUsage(counters[i].Usage())
+		}
+		results = append(results, res)
+		if err := allChecks.add(run.check.Category, res); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := estimator.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: some savings could not be estimated:", err)
+	}
+	if err := cache.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: unable to save pricing cache:", err)
+	}
+	return &scan{checks: allChecks, results: results, cfg: cfg, caller: caller, targets: targets}, nil
+}
+
+// loadSuppressor loads the files passed to the suppressions and baseline flags.
+// Expired suppressions and tag suppressions of checks whose findings have no
+// tags never apply and are reported as a warning.
+func loadSuppressor() (suppression.Suppressor, error) {
+	suppressor := suppression.Suppressor{Now: time.Now()}
+	if suppressionsFile != "" {
+		rules, err := suppression.Load(suppressionsFile)
+		if err != nil {
+			return suppressor, err
+		}
+		for _, rule := range rules.Expired(suppressor.Now) {
+			fmt.Fprintf(os.Stderr, "Warning: suppression expired on %s and no longer applies: %s\n", rule.Expires, rule.Reason)
+		}
+		for _, id := range internalAws.CheckIds() {
+			check, _ := internalAws.NewCheck(id)
+			for _, rule := range rules.Suppressions {
+				if rule.Unmatchable(check.Metadata()) {
+					fmt.Fprintf(os.Stderr, "Warning: suppression tag (%s) never applies since findings of check (%s) have no tags: %s\n", rule.Tag, rule.CheckId, rule.Reason)
+				}
+			}
+		}
+		suppressor.Rules = rules
+	}
+	if baselineFile != "" {
+		baseline, err := suppression.LoadBaseline(baselineFile)
+		if err != nil {
+			return suppressor, err
+		}
+		suppressor.Baseline = baseline
+	}
+	return suppressor, nil
+}
+
+// addScanFlags registers the flags that control which checks are run and
+// where on a command that runs checks.
+func addScanFlags(c *cobra.Command) {
+	c.Flags().StringSliceVarP(&includeChecks, "include-checks", "i", []string{}, "A list of all the checks you wish to run.")
+	c.Flags().StringSliceVarP(&excludeChecks, "exclude-checks", "e", []string{}, "A list of checks to exclude from running.")
+	c.Flags().StringSliceVarP(&regions, "regions", "r", []string{}, "A list of regions to run regional checks in. Use \"all\" to run in every region enabled for the account. Default: the configured aws region.")
+	c.Flags().BoolVar(&organization, "organization", false, "Run checks against every active account in the AWS Organization. Requires credentials for the management account or a delegated administrator.")
+	c.Flags().StringVar(&orgRoleName, "org-role-name", "OrganizationAccountAccessRole", "The name of the role to assume in each member account when the organization flag is set.")
+	c.Flags().StringVar(&orgExternalId, "org-external-id", "", "An optional external id to use when assuming the org-role-name role.")
+	c.Flags().StringVar(&pricingCache, "pricing-cache", "", "A path to the file used to cache prices from the AWS Pricing API. Default: ckia/pricing.json in the user cache directory.")
+	c.Flags().StringVar(&suppressionsFile, "suppressions", "", "A path to a yaml file of suppression rules. Findings matching a rule are listed as suppressed instead of flagged.")
+	c.Flags().StringVar(&baselineFile, "baseline", "", "A path to a baseline file created with the baseline command. Findings in the baseline are listed as suppressed instead of flagged.")
+	c.Flags().IntVar(&workers, "workers", scheduler.DefaultWorkers, "The number of checks to run at once.")
+	c.Flags().Float64Var(&apiRateLimit, "api-rate-limit", client.DefaultRequestsPerSecond, "The number of AWS API calls per second allowed to each service in each region before calls are delayed. Use 0 for no limit.")
+	c.Flags().DurationVar(&checkTimeout, "check-timeout", 10*time.Minute, "The time a check may run in a single account and region before it is reported as failed_to_run. Use 0 for no timeout.")
+	c.Flags().StringSliceVar(&tagFilters, "tag-filter", []string{}, "Only evaluate resources matching every tag filter: key=value, key for resources with the tag, !key for resources without the tag or key!=value. Account level checks are not filtered.")
+	c.Flags().StringSliceVar(&excludeTags, "exclude-tag", []string{}, "Do not evaluate resources with any of these tags, either key or key=value.")
+}
+
+// outFormats returns the formats supported by the out-format flag.
+func outFormats() []string {
+	return append([]string{formatJSON, formatASFF}, report.Formats()...)
+}
+
+// writeOutput calls write with the out-file, or with stdout when no out-file is
+// set.
+func writeOutput(write func(w io.Writer) error) error {
+	if outFile == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return write(f)
+}
+
+// securityHubProduct returns the Security Hub product of the configured
+// credentials and region, which findings are exported for and published to.
+func securityHubProduct(s *scan) internalSecurityHub.Product {
+	return internalSecurityHub.Product{Partition: s.caller.Partition, Region: s.cfg.Region, AccountId: s.caller.Id}
+}
+
+// publishFindings imports the findings of the scan into Security Hub in the
+// configured region. The findings of each account are published with the aws
+// config of that account, since Security Hub only accepts findings of the
+// account of the product they are imported into. Findings rejected by Security
+// Hub are an error.
+func publishFindings(ctx context.Context, s *scan) error {
+	if s.cfg.Region == "" {
+		return errors.New("no region configured to publish findings to Security Hub, set AWS_REGION or use the region flag")
+	}
+	configs := map[string]aws.Config{}
+	for _, target := range s.targets {
+		configs[target.account.Id] = target.cfg
+	}
+
+	accounts := internalSecurityHub.ByAccount(internalSecurityHub.Findings(securityHubProduct(s), s.results, time.Now()))
+	accountIds := make([]string, 0, len(accounts))
+	for accountId := range accounts {
+		accountIds = append(accountIds, accountId)
+	}
+	sort.Strings(accountIds)
+
+	var published, rejected int
+	for _, accountId := range accountIds {
+		cfg, ok := configs[accountId]
+		if !ok {
+			cfg = s.cfg
+		}
+		result, err := internalSecurityHub.Publish(ctx, internalSecurityHub.NewClient(cfg, securityHubEndpoint), accounts[accountId])
+		if err != nil {
+			return fmt.Errorf("unable to publish findings of account (%s) to Security Hub: %w", accountId, err)
+		}
+		published += result.SuccessCount
+		for _, failed := range result.Failed {
+			fmt.Fprintf(os.Stderr, "Security Hub rejected finding (%s): %s %s\n", aws.ToString(failed.Id), aws.ToString(failed.ErrorCode), aws.ToString(failed.ErrorMessage))
+		}
+		rejected += len(result.Failed)
+	}
+	fmt.Fprintf(os.Stderr, "Published %d finding(s) to Security Hub\n", published)
+	if rejected > 0 {
+		return cmd.CheckError(fmt.Errorf("%d finding(s) rejected by Security Hub", rejected))
+	}
+	return nil
+}
+
+const (
+	allRegions = "all"
+	formatJSON = "json"
+	formatASFF = "asff"
+)
+
 var includeChecks []string
 var excludeChecks []string
 var outFile string
 var outFormat string
+var detail bool
+var publishSecurityHub bool
+var securityHubEndpoint string
+var failOn []string
+var historyDir string
+var noHistory bool
+var regions []string
+var organization bool
+var orgRoleName string
+var orgExternalId string
+var pricingCache string
+var suppressionsFile string
+var baselineFile string
+var workers int
+var checkTimeout time.Duration
+var apiRateLimit float64
+var tagFilters []string
+var excludeTags []string
+var showTags []string
 
 // checkCmd represents the check command
 var checkCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Run available checks for aws",
-	Long:  `Run available opinionated checks for aws cloud.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	Long: `Run available opinionated checks for aws cloud.
+
+Exit codes:
+  0  no findings matched the fail-on policy
+  1  findings matched the fail-on policy
+  2  invalid command, flags or config file
+  3  the checks could not be run, or some checks failed to run`,
+	RunE: func(c *cobra.Command, args []string) error {
 		// Validate flags
-		if !common.StringSliceContains([]string{"json"}, outFormat) {
-			return errors.New("unsupported format provided to out-format flag")
+		if !common.StringSliceContains(outFormats(), outFormat) {
+			return cmd.UsageError(errors.New("unsupported format provided to out-format flag"))
+		}
+		failPolicy, err := policy.Parse(failOn)
+		if err != nil {
+			return cmd.UsageError(err)
 		}
 
 		ctx := context.Background()
-		cfg, err := config.LoadDefaultConfig(ctx)
+		s, err := runChecks(ctx)
 		if err != nil {
 			return err
 		}
-		conn := client.InitiateClient(cfg)
-		allChecks := Checks{}
-		checksMap := internalAws.BuildChecksMap()
-		checksList := []string{}
-		for k := range checksMap {
-			checksList = append(checksList, k)
-		}
-		bar := progressbar.NewOptions(len(checksList),
-			progressbar.OptionSetWriter(ansi.NewAnsiStdout()),
-			progressbar.OptionEnableColorCodes(true),
-			progressbar.OptionFullWidth(),
-			progressbar.OptionShowCount(),
-			progressbar.OptionShowElapsedTimeOnFinish(),
-			progressbar.OptionSetDescription(fmt.Sprintf("Running [cyan][%d][reset] ckia Checks...", len(checksList))),
-			progressbar.OptionSetTheme(progressbar.Theme{
-				Saucer:        "[green]=[reset]",
-				SaucerHead:    "[green]>[reset]",
-				SaucerPadding: " ",
-				BarStart:      "[",
-				BarEnd:        "]",
-			}))
-		errors := parallel.Map(checksList, func(k string) error {
-			if (len(includeChecks) > 0 && common.StringSliceContains(includeChecks, k)) || (len(excludeChecks) > 0 && !common.StringSliceContains(excludeChecks, k)) || (len(includeChecks) == 0 && len(excludeChecks) == 0) {
-				if strings.Contains(k, "aws:cost") {
-					res, _ := common.Call(k, checksMap, common.MethodNameRun, ctx, conn)
-					if err != nil {
-						return err
-					}
-					if res != nil {
-						allChecks.CostOptimization = append(allChecks.CostOptimization, res)
-					}
-				}
-				if strings.Contains(k, "aws:security") {
-					res, _ := common.Call(k, checksMap, common.MethodNameRun, ctx, conn)
-					if err != nil {
-						return err
-					}
-					if res != nil {
-						allChecks.Security = append(allChecks.Security, res)
-					}
-				}
+
+		if err := writeResults(s); err != nil {
+			return err
+		}
+		if !noHistory {
+			if err := recordRun(s); err != nil {
+				fmt.Fprintln(os.Stderr, "Warning: unable to record run in history:", err)
 			}
-			bar.Add(1)
-			return nil
-		})
+		}
 
-		for _, err := range errors {
-			if err != nil {
+		// Results are written before publishing, so they are not lost when
+		// Security Hub rejects findings.
+		if publishSecurityHub {
+			if err := publishFindings(ctx, s); err != nil {
 				return err
 			}
 		}
 
-		json, err := json.Marshal(allChecks)
-		if err != nil {
-			fmt.Print("An Error happened when marshaling json")
+		if failed := failedToRun(s.results); failed > 0 {
+			return cmd.CheckError(fmt.Errorf("%d check run(s) failed to run", failed))
 		}
-		fmt.Println()
-		resp, err := common.PrettyString(string(json))
+		if failures := failPolicy.Failures(s.results); failures > 0 {
+			return cmd.FindingsError(failures)
+		}
+		return nil
+	},
+}
+
+// writeResults writes the results of the scan in the out-format.
+func writeResults(s *scan) error {
+	switch outFormat {
+	case formatJSON:
+	case formatASFF:
+		findings := internalSecurityHub.Findings(securityHubProduct(s), s.results, time.Now())
+		return writeOutput(func(w io.Writer) error {
+			return internalSecurityHub.WriteFindings(w, findings)
+		})
+	default:
+		opts := report.Options{Detail: detail, Excluded: excludedChecks(), Tags: showTags}
+		return writeOutput(func(w io.Writer) error {
+			return report.Write(outFormat, w, s.results, opts)
+		})
+	}
+
+	json, err := json.Marshal(s.checks)
+	if err != nil {
+		fmt.Print("An Error happened when marshaling json")
+	}
+	resp, err := common.PrettyString(string(json))
+	if err != nil {
+		return err
+	}
+
+	if outFile != "" {
+		err = ioutil.WriteFile(outFile, json, 0644)
+
 		if err != nil {
 			return err
 		}
+	} else {
+		fmt.Println(resp)
+	}
 
-		if outFile != "" {
-			err = ioutil.WriteFile(outFile, json, 0644)
+	return nil
+}
 
-			if err != nil {
-				return err
-			}
-		} else {
-			fmt.Println(resp)
-		}
+// recordRun saves the results of the scan as a run in the history store.
+func recordRun(s *scan) error {
+	store, err := history.NewStore(historyDir)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(s.checks)
+	if err != nil {
+		return err
+	}
+	_, err = store.Save(time.Now(), content)
+	return err
+}
 
-		return nil
-	},
+// failedToRun returns the number of results of check runs that failed to run.
+func failedToRun(results []common.Result) int {
+	var failed int
+	for _, res := range results {
+		if res.Summary().Status == common.StatusFailedToRun {
+			failed++
+		}
+	}
+	return failed
 }
 
 func init() {
 	cmd.AwsCmd.AddCommand(checkCmd)
-	checkCmd.Flags().StringSliceVarP(&includeChecks, "include-checks", "i", []string{}, "A list of all the checks you wish to run.")
-	checkCmd.Flags().StringSliceVarP(&excludeChecks, "exclude-checks", "e", []string{}, "A list of checks to exclude from running.")
+	addScanFlags(checkCmd)
 	checkCmd.Flags().StringVarP(&outFile, "out-file", "o", "", "A path to a file to store check results.")
-	checkCmd.Flags().StringVarP(&outFormat, "out-format", "f", "json", "The file output format for check results. Default: json (currently only json is supported).")
+	checkCmd.Flags().StringSliceVar(&showTags, "show-tags", []string{}, "A list of tag keys, e.g. owner,cost-center, shown in a column of their own for every finding in the table, markdown, csv and html formats.")
+	checkCmd.Flags().BoolVar(&detail, "detail", false, "Include the check specific detail of every finding in the output. Currently used by the csv format to add a column per detail field.")
+	checkCmd.Flags().BoolVar(&publishSecurityHub, "publish-security-hub", false, "Import the findings into AWS Security Hub in the configured region with BatchImportFindings.")
+	checkCmd.Flags().StringVar(&securityHubEndpoint, "security-hub-endpoint", "", "An optional endpoint url to publish Security Hub findings to instead of the regional Security Hub endpoint.")
+	checkCmd.Flags().StringVar(&historyDir, "history-dir", "", "The directory to record the check run in (default is ckia/history in the user config directory).")
+	checkCmd.Flags().BoolVar(&noHistory, "no-history", false, "Do not record the check run in the history directory.")
+	checkCmd.Flags().StringSliceVar(&failOn, "fail-on", []string{policy.None}, "Exit with code 1 when findings match any of these conditions: any, none, severity>=<severity>, category=<category> or check=<check id>. Severity supports =, !=, >, >=, < and <=.")
+	checkCmd.Flags().StringVarP(&outFormat, "out-format", "f", formatJSON, fmt.Sprintf("The output format for check results, one of: %s.", strings.Join(outFormats(), ", ")))
 }
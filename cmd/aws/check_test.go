@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/brittandeyoung/ckia/cmd"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/report"
+)
+
+func newTestScan(t *testing.T) *scan {
+	res := &common.StoredResult{
+		Check: common.Check{
+			Id:       "ckia:aws:cost:UnassociatedElasticIPAddresses",
+			Category: common.CategoryCostOptimization,
+			Severity: common.SeverityLow,
+			Name:     "Unassociated Elastic IP Addresses",
+		},
+		CheckResult: common.NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	res.ResourcesEvaluated = 1
+	res.AddFinding(common.Finding{
+		ResourceId: "eipalloc-01",
+		AccountId:  "123456789011",
+		Region:     "us-east-1",
+		Reason:     "not associated",
+	})
+	res.Evaluate(res.Severity)
+
+	s := &scan{checks: newChecks(), results: []common.Result{res}}
+	if err := s.checks.add(res.Category, res); err != nil {
+		t.Fatalf("Unexpected error adding result: %s", err)
+	}
+	return s
+}
+
+// captureStdout returns everything written to stdout while f runs.
+func captureStdout(t *testing.T, f func() error) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(r)
+		out <- content
+	}()
+	ferr := f()
+	w.Close()
+	content := <-out
+	if ferr != nil {
+		t.Fatalf("Unexpected error writing results: %s", ferr)
+	}
+	return string(content)
+}
+
+func TestWriteResults_stdoutOnlyHoldsReport(t *testing.T) {
+	s := newTestScan(t)
+	defer func(format string) { outFormat = format }(outFormat)
+
+	for _, format := range outFormats() {
+		outFormat = format
+		got := captureStdout(t, func() error { return writeResults(s) })
+
+		switch format {
+		case formatJSON, formatASFF:
+			if !json.Valid([]byte(got)) || (got[0] != '{' && got[0] != '[') {
+				t.Fatalf("Stdout of format (%s) should only hold json, Got %q", format, got)
+			}
+		default:
+			var want bytes.Buffer
+			opts := report.Options{Excluded: excludedChecks()}
+			if err := report.Write(format, &want, s.results, opts); err != nil {
+				t.Fatalf("Unexpected error writing format (%s): %s", format, err)
+			}
+			if got != want.String() {
+				t.Fatalf("Stdout of format (%s) should only hold the report, Got %q", format, got)
+			}
+		}
+	}
+}
+
+func TestPublishFindings_byAccount(t *testing.T) {
+	var mu sync.Mutex
+	imported := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input struct {
+			Findings []struct {
+				AwsAccountId string
+			}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Errorf("Unexpected request body: %s", err)
+		}
+		// The access key of each account identifies the credentials used.
+		accessKey := strings.Split(strings.Split(r.Header.Get("Authorization"), "Credential=")[1], "/")[0]
+		mu.Lock()
+		for _, finding := range input.Findings {
+			imported[accessKey] = append(imported[accessKey], finding.AwsAccountId)
+		}
+		mu.Unlock()
+
+		failed := []interface{}{}
+		if accessKey == "AKIDMEMBER" {
+			failed = append(failed, map[string]string{"Id": "finding", "ErrorCode": "AccessDenied", "ErrorMessage": "denied"})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"SuccessCount": len(input.Findings) - len(failed), "FailedCount": len(failed), "FailedFindings": failed})
+	}))
+	defer server.Close()
+	defer func(endpoint string) { securityHubEndpoint = endpoint }(securityHubEndpoint)
+	securityHubEndpoint = server.URL
+
+	config := func(accessKey string) aws.Config {
+		return aws.Config{Region: "us-east-1", Credentials: credentials.NewStaticCredentialsProvider(accessKey, "secret", "")}
+	}
+	s := newTestScan(t)
+	member := &common.StoredResult{Check: s.results[0].Metadata(), CheckResult: common.NewCheckResult("210987654321", "us-east-1", nil)}
+	member.AddFinding(common.Finding{ResourceId: "eipalloc-02", AccountId: "210987654321", Region: "us-east-1"})
+	s.results = append(s.results, member)
+	s.cfg = config("AKIDMANAGEMENT")
+	s.caller = client.Account{Id: "123456789011", Partition: "aws"}
+	s.targets = []scanTarget{
+		{account: client.Account{Id: "123456789011"}, cfg: config("AKIDMANAGEMENT")},
+		{account: client.Account{Id: "210987654321"}, cfg: config("AKIDMEMBER")},
+	}
+
+	err := publishFindings(context.Background(), s)
+	var exitErr *cmd.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != cmd.ExitCheckErrors {
+		t.Fatalf("Rejected findings should be an error, Got %v", err)
+	}
+	if len(imported) != 2 || imported["AKIDMANAGEMENT"][0] != "123456789011" || imported["AKIDMEMBER"][0] != "210987654321" {
+		t.Fatalf("Findings should be published with the config of their account, Got %v", imported)
+	}
+}
@@ -0,0 +1,159 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/brittandeyoung/ckia/cmd"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Verify the credentials and permissions used to run checks for aws",
+	Long: `Verify the configured credentials with STS GetCallerIdentity, report the resolved profile, region and partition, and simulate the IAM policies of the caller for the actions of every check selected by the include-checks and exclude-checks flags.
+Checks missing a permission would fail with AccessDenied during a scan. Set the organization and publish-security-hub flags to also verify the permissions of those features. Simulating policies requires the iam:SimulatePrincipalPolicy permission, and iam:GetRole to find the path of an assumed role.`,
+	RunE: func(c *cobra.Command, args []string) error {
+		ctx := context.Background()
+		opts := cmd.AwsConfigOptions()
+		cfg, err := client.LoadConfig(ctx, opts)
+		if err != nil {
+			return cmd.UsageError(err)
+		}
+		conn := client.InitiateClient(cfg)
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Profile:\t%s\n", valueOrNone(client.Profile(cfg)))
+		fmt.Fprintf(tw, "Region:\t%s\n", valueOrNone(cfg.Region))
+		if opts.RoleArn != "" {
+			fmt.Fprintf(tw, "Role:\t%s\n", opts.RoleArn)
+		}
+		if opts.EndpointURL != "" {
+			fmt.Fprintf(tw, "Endpoint:\t%s\n", opts.EndpointURL)
+		}
+		if cfg.Credentials == nil {
+			tw.Flush()
+			return cmd.CheckError(errors.New("no credentials configured"))
+		}
+		creds, err := cfg.Credentials.Retrieve(ctx)
+		if err != nil {
+			tw.Flush()
+			return cmd.CheckError(fmt.Errorf("unable to retrieve credentials: %w", err))
+		}
+		fmt.Fprintf(tw, "Credentials:\t%s\n", creds.Source)
+		identity, err := conn.STS.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			tw.Flush()
+			return cmd.CheckError(fmt.Errorf("unable to verify credentials: %w", err))
+		}
+		callerArn := aws.ToString(identity.Arn)
+		fmt.Fprintf(tw, "Account:\t%s\n", aws.ToString(identity.Account))
+		fmt.Fprintf(tw, "Caller:\t%s\n", callerArn)
+		if parsed, err := arn.Parse(callerArn); err == nil {
+			fmt.Fprintf(tw, "Partition:\t%s\n", parsed.Partition)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		// Permissions that cannot be simulated are not known to be missing, so
+		// they are only reported as a warning.
+		checks := selectedChecks()
+		principalArn, err := client.PrincipalArn(callerArn)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: permissions not verified:", err)
+			return nil
+		}
+		// The path of an assumed role is not part of the caller arn, and
+		// policies of a role with a path can only be simulated with its full arn.
+		if roleArn, err := client.RoleArnWithPath(ctx, conn.IAM, principalArn); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: the path of %s could not be found with iam:GetRole, simulating its policies without a path: %s\n", principalArn, err)
+		} else {
+			principalArn = roleArn
+		}
+		denied, err := client.DeniedActions(ctx, conn.IAM, principalArn, internalAws.Permissions(checks, scanOptions()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: permissions not verified, unable to simulate the policies of %s: %s\n", principalArn, err)
+			return nil
+		}
+
+		return writePermissionsReport(os.Stdout, scanOptions().Permissions(), checks, denied)
+	},
+}
+
+func init() {
+	cmd.AwsCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringSliceVarP(&includeChecks, "include-checks", "i", []string{}, "A list of the checks to verify permissions for.")
+	doctorCmd.Flags().StringSliceVarP(&excludeChecks, "exclude-checks", "e", []string{}, "A list of checks to skip verifying permissions for.")
+	doctorCmd.Flags().BoolVar(&organization, "organization", false, "Also verify the permissions to run checks against every account in the AWS Organization.")
+	doctorCmd.Flags().BoolVar(&publishSecurityHub, "publish-security-hub", false, "Also verify the permissions to import findings into AWS Security Hub.")
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// missingPermissions returns the permissions that were denied.
+func missingPermissions(permissions []string, denied []string) []string {
+	var missing []string
+	for _, permission := range permissions {
+		if common.StringSliceContains(denied, permission) {
+			missing = append(missing, permission)
+		}
+	}
+	return missing
+}
+
+// writePermissionsReport writes the missing permissions of the scan and of
+// every check as a table, and returns an error when any permission is denied
+// since the scan, or the checks missing it, would fail.
+func writePermissionsReport(w io.Writer, scanPermissions []string, checks []common.Check, denied []string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMISSING PERMISSIONS")
+	missingScan := missingPermissions(scanPermissions, denied)
+	writePermissions(tw, "(scan)", missingScan)
+	var missing int
+	for _, check := range checks {
+		missingCheck := missingPermissions(check.Permissions, denied)
+		if len(missingCheck) > 0 {
+			missing++
+		}
+		writePermissions(tw, check.Id, missingCheck)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(missingScan) > 0 {
+		return cmd.CheckError(fmt.Errorf("the scan is missing permissions and would fail: %s", strings.Join(missingScan, ", ")))
+	}
+	if missing > 0 {
+		return cmd.CheckError(fmt.Errorf("%d check(s) are missing permissions and would fail to run", missing))
+	}
+	return nil
+}
+
+// writePermissions writes the row of a check to the permissions table.
+func writePermissions(w io.Writer, name string, missing []string) {
+	status := "ok"
+	if len(missing) > 0 {
+		status = "denied"
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\n", name, status, strings.Join(missing, ", "))
+}
@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/cmd"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestWritePermissionsReport(t *testing.T) {
+	scanPermissions := []string{"ec2:DescribeRegions", "organizations:ListAccounts", "sts:GetCallerIdentity"}
+	checks := []common.Check{{Id: "ckia:aws:security:RootAccountMissingMFA", Permissions: []string{"iam:GetAccountSummary"}}}
+
+	cases := map[string]struct {
+		denied []string
+		row    string
+		fails  bool
+	}{
+		"allowed":          {nil, "(scan)", false},
+		"denied scan":      {[]string{"organizations:ListAccounts"}, "(scan)                                   denied  organizations:ListAccounts", true},
+		"denied for check": {[]string{"iam:GetAccountSummary"}, "ckia:aws:security:RootAccountMissingMFA  denied  iam:GetAccountSummary", true},
+	}
+
+	for name, c := range cases {
+		var buf bytes.Buffer
+		err := writePermissionsReport(&buf, scanPermissions, checks, c.denied)
+		var exitErr *cmd.ExitError
+		if c.fails && (!errors.As(err, &exitErr) || exitErr.Code != cmd.ExitCheckErrors) {
+			t.Fatalf("%s: expected an error exiting with code %d, Got %v", name, cmd.ExitCheckErrors, err)
+		}
+		if !c.fails && err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if !strings.Contains(buf.String(), c.row) {
+			t.Fatalf("%s: report should contain %q, Got:\n%s", name, c.row, buf.String())
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/brittandeyoung/ckia/cmd"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/spf13/cobra"
+)
+
+var iamPolicyOutFile string
+
+// iamPolicyCmd represents the iam-policy command
+var iamPolicyCmd = &cobra.Command{
+	Use:   "iam-policy",
+	Short: "Print the IAM policy needed to run checks for aws",
+	Long: `Print the least privilege IAM policy needed to run the checks selected by the include-checks and exclude-checks flags.
+The policy allows every IAM action the selected checks call, along with the actions every scan calls, and can be attached to a read only role for ckia.
+Set the organization flag to allow listing the accounts of the organization and assuming a role in each of them, and the publish-security-hub flag to allow importing findings into Security Hub.`,
+	RunE: func(c *cobra.Command, args []string) error {
+		policy, err := json.MarshalIndent(internalAws.NewIAMPolicy(selectedChecks(), scanOptions()), "", "    ")
+		if err != nil {
+			return err
+		}
+		policy = append(policy, '\n')
+
+		if iamPolicyOutFile == "" {
+			_, err = os.Stdout.Write(policy)
+			return err
+		}
+		return os.WriteFile(iamPolicyOutFile, policy, 0644)
+	},
+}
+
+func init() {
+	cmd.AwsCmd.AddCommand(iamPolicyCmd)
+	iamPolicyCmd.Flags().StringSliceVarP(&includeChecks, "include-checks", "i", []string{}, "A list of the checks to include in the policy.")
+	iamPolicyCmd.Flags().StringSliceVarP(&excludeChecks, "exclude-checks", "e", []string{}, "A list of checks to exclude from the policy.")
+	iamPolicyCmd.Flags().BoolVar(&organization, "organization", false, "Include the permissions to run checks against every account in the AWS Organization.")
+	iamPolicyCmd.Flags().BoolVar(&publishSecurityHub, "publish-security-hub", false, "Include the permissions to import findings into AWS Security Hub.")
+	iamPolicyCmd.Flags().StringVarP(&iamPolicyOutFile, "out-file", "o", "", "A path to a file to store the policy.")
+}
+
+// scanOptions returns the optional features of a scan set by the organization
+// and publish-security-hub flags.
+func scanOptions() internalAws.ScanOptions {
+	return internalAws.ScanOptions{Organization: organization, PublishSecurityHub: publishSecurityHub}
+}
@@ -3,7 +3,6 @@ package aws
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/brittandeyoung/ckia/cmd"
 	internalAws "github.com/brittandeyoung/ckia/internal/aws"
@@ -17,20 +16,12 @@ var listCmd = &cobra.Command{
 	Short: "List available checks for aws",
 	Long:  `List the available opinionated checks for aws cloud.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		allChecks := Checks{}
-		checksMap := internalAws.BuildChecksMap()
-		for k := range checksMap {
-			if strings.Contains(k, "aws:cost") {
-				res, _ := common.Call(k, checksMap, common.MethodNameList)
-				if res != nil {
-					allChecks.CostOptimization = append(allChecks.CostOptimization, res)
-				}
-			}
-			if strings.Contains(k, "aws:security") {
-				res, _ := common.Call(k, checksMap, common.MethodNameList)
-				if res != nil {
-					allChecks.Security = append(allChecks.Security, res)
-				}
+		allChecks := newChecks()
+		for _, id := range internalAws.CheckIds() {
+			check, _ := internalAws.NewCheck(id)
+			metadata := check.Metadata()
+			if err := allChecks.add(metadata.Category, metadata); err != nil {
+				return err
 			}
 		}
 		json, err := json.Marshal(allChecks)
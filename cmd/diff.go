@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+var diffOutFile string
+var diffOutFormat string
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD NEW",
+	Short: "Compare the findings of two check runs",
+	Long: `Compare two json check results written with the out-file flag of a check command.
+Findings are matched by check id, account, region and resource id and reported as new, resolved or unchanged, along with the change in estimated monthly savings. Findings of a check that failed to run in the new results are reported as not compared.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !common.StringSliceContains(diff.Formats(), diffOutFormat) {
+			return UsageError(errors.New("unsupported format provided to out-format flag"))
+		}
+
+		oldResults, err := common.LoadResults(args[0])
+		if err != nil {
+			return UsageError(err)
+		}
+		newResults, err := common.LoadResults(args[1])
+		if err != nil {
+			return UsageError(err)
+		}
+		d := diff.Compare(oldResults, newResults)
+
+		if diffOutFile == "" {
+			return diff.Write(diffOutFormat, os.Stdout, d)
+		}
+		f, err := os.Create(diffOutFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return diff.Write(diffOutFormat, f, d)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVarP(&diffOutFile, "out-file", "o", "", "A path to a file to store the diff.")
+	diffCmd.Flags().StringVarP(&diffOutFormat, "out-format", "f", diff.FormatTable, fmt.Sprintf("The output format for the diff, one of: %s.", strings.Join(diff.Formats(), ", ")))
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes of ckia, so CI pipelines can tell a failed policy apart from a
+// broken run.
+const (
+	ExitOK = 0
+	// ExitFindings means findings matched the fail-on policy.
+	ExitFindings = 1
+	// ExitUsage means an invalid command, flag or config file.
+	ExitUsage = 2
+	// ExitCheckErrors means the checks could not be run, or some checks failed
+	// to run and the results are incomplete.
+	ExitCheckErrors = 3
+)
+
+// ExitError is an error that exits ckia with Code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// UsageError returns err as an error exiting with ExitUsage.
+func UsageError(err error) error {
+	return &ExitError{Code: ExitUsage, Err: err}
+}
+
+// CheckError returns err as an error exiting with ExitCheckErrors.
+func CheckError(err error) error {
+	return &ExitError{Code: ExitCheckErrors, Err: err}
+}
+
+// FindingsError returns an error exiting with ExitFindings for count findings
+// matching the fail-on policy.
+func FindingsError(count int) error {
+	return &ExitError{Code: ExitFindings, Err: fmt.Errorf("%d finding(s) matched the fail-on policy", count)}
+}
+
+// exitCode returns the exit code for err. Errors without an exit code are
+// returned by cobra for an unknown command or invalid flags before a command
+// runs, or by a command while it runs.
+func exitCode(err error, started bool) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if started {
+		return ExitCheckErrors
+	}
+	return ExitUsage
+}
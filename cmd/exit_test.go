@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		started bool
+		want    int
+	}{
+		{"invalid flag", errors.New("unknown flag: --bogus"), false, ExitUsage},
+		{"command error", errors.New("no region configured"), true, ExitCheckErrors},
+		{"usage error", UsageError(errors.New("unsupported format")), true, ExitUsage},
+		{"check error", CheckError(errors.New("1 check run(s) failed to run")), true, ExitCheckErrors},
+		{"findings", FindingsError(2), true, ExitFindings},
+		{"wrapped findings", fmt.Errorf("check: %w", FindingsError(2)), true, ExitFindings},
+	}
+
+	for _, c := range cases {
+		if got := exitCode(c.err, c.started); got != c.want {
+			t.Errorf("Exit code for %s should be %d, Got %d", c.name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/history"
+	"github.com/brittandeyoung/ckia/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var historyDir string
+var historyOutFormat string
+var historyShowOutFormat string
+var trendAccountId string
+var trendRegion string
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse the results of previous check runs",
+	Long: `Browse the results of previous check runs.
+Every check run is recorded in the history directory unless the no-history flag is set.`,
+}
+
+// historyListCmd represents the history list command
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded check runs",
+	Long:  `List every recorded check run from oldest to newest with its flagged resources and estimated monthly savings.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !common.StringSliceContains(history.Formats(), historyOutFormat) {
+			return UsageError(errors.New("unsupported format provided to out-format flag"))
+		}
+		store, runs, err := historyRuns()
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			fmt.Fprintf(os.Stderr, "No runs recorded in %s\n", store.Dir)
+		}
+		summaries, err := history.Trend(runs, history.Filter{})
+		if err != nil {
+			return err
+		}
+		return history.WriteSummaries(historyOutFormat, os.Stdout, summaries)
+	},
+}
+
+// historyShowCmd represents the history show command
+var historyShowCmd = &cobra.Command{
+	Use:   "show RUN",
+	Short: "Show the results of a recorded check run",
+	Long:  `Show the results of a recorded check run in any output format of the check command. Use latest to show the newest run.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formats := append([]string{history.FormatJSON}, report.Formats()...)
+		if !common.StringSliceContains(formats, historyShowOutFormat) {
+			return UsageError(errors.New("unsupported format provided to out-format flag"))
+		}
+		store, err := history.NewStore(historyDir)
+		if err != nil {
+			return err
+		}
+		run, err := store.Load(args[0])
+		if err != nil {
+			return UsageError(err)
+		}
+
+		if historyShowOutFormat == history.FormatJSON {
+			resp, err := common.PrettyString(string(run.Results))
+			if err != nil {
+				return err
+			}
+			fmt.Println(resp)
+			return nil
+		}
+		results, err := run.CheckResults()
+		if err != nil {
+			return err
+		}
+		return report.Write(historyShowOutFormat, os.Stdout, results, report.Options{})
+	},
+}
+
+// historyTrendCmd represents the history trend command
+var historyTrendCmd = &cobra.Command{
+	Use:   "trend CHECK",
+	Short: "Show the findings of a check over time",
+	Long:  `Show the flagged resources, suppressed findings and estimated monthly savings of a check in every recorded run. The check is the full check id or the last segment of it.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !common.StringSliceContains(history.Formats(), historyOutFormat) {
+			return UsageError(errors.New("unsupported format provided to out-format flag"))
+		}
+		_, runs, err := historyRuns()
+		if err != nil {
+			return err
+		}
+		trend, err := history.Trend(runs, history.Filter{CheckId: args[0], AccountId: trendAccountId, Region: trendRegion})
+		if err != nil {
+			return err
+		}
+		if len(trend) == 0 {
+			fmt.Fprintf(os.Stderr, "No recorded runs of check %s\n", args[0])
+		}
+		return history.WriteSummaries(historyOutFormat, os.Stdout, trend)
+	},
+}
+
+func historyRuns() (history.Store, []*history.Run, error) {
+	store, err := history.NewStore(historyDir)
+	if err != nil {
+		return store, nil, err
+	}
+	runs, err := store.Runs()
+	return store, runs, err
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd, historyShowCmd, historyTrendCmd)
+	historyCmd.PersistentFlags().StringVar(&historyDir, "history-dir", "", "The directory check runs are recorded in (default is ckia/history in the user config directory).")
+	for _, c := range []*cobra.Command{historyListCmd, historyTrendCmd} {
+		c.Flags().StringVarP(&historyOutFormat, "out-format", "f", history.FormatTable, fmt.Sprintf("The output format, one of: %s.", strings.Join(history.Formats(), ", ")))
+	}
+	historyShowCmd.Flags().StringVarP(&historyShowOutFormat, "out-format", "f", history.FormatJSON, fmt.Sprintf("The output format for the check results, one of: %s, %s.", history.FormatJSON, strings.Join(report.Formats(), ", ")))
+	historyTrendCmd.Flags().StringVar(&trendAccountId, "account", "", "Only include the results of this account id.")
+	historyTrendCmd.Flags().StringVar(&trendRegion, "region", "", "Only include the results of this region.")
+}
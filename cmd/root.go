@@ -10,17 +10,26 @@ import (
 
 var cfgFile string
 
+// started is set once a command starts running, after its flags and arguments
+// have been validated.
+var started bool
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "ckia",
 	Short: "An open source tool for making recommendations for target cloud account.",
 	Long:  `An open source tool for making recommendations for target cloud account.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Errors returned once the command runs are not caused by its usage.
+		cmd.SilenceUsage = true
+		started = true
+	},
 }
 
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err, started))
 	}
 }
 
@@ -0,0 +1,148 @@
+// Package awstest runs aws checks against fixtures of recorded AWS API
+// responses, so the Run method of every check can be tested offline.
+//
+// The fixtures in the testdata directory of each check package are written by
+// hand to cover the cases of each check, not recorded from a real account, so
+// they only reflect the documented shape of the API responses. Set RecordEnv
+// to replace them with recorded responses.
+package awstest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+	internalPricing "github.com/brittandeyoung/ckia/internal/pricing"
+)
+
+const (
+	// RecordEnv is the environment variable that records fixtures instead of
+	// replaying them. When set, tests call the account of the default aws
+	// config and overwrite their fixtures with its responses. Recorded fixtures
+	// should be reviewed for account details before they are committed.
+	RecordEnv = "CKIA_RECORD_FIXTURES"
+
+	// AccountId is the account of the clients returned by NewClient.
+	AccountId = "123456789011"
+)
+
+// NewClient returns a client replaying the fixture at path, e.g.
+// testdata/IdleDBInstances.json. Prices are resolved from the fixture with an
+// empty price cache.
+func NewClient(t *testing.T, path string) client.AWSClient {
+	t.Helper()
+
+	var fixture *client.Fixture
+	var cfg aws.Config
+	if os.Getenv(RecordEnv) != "" {
+		var err error
+		cfg, err = config.LoadDefaultConfig(context.Background())
+		if err != nil {
+			t.Fatalf("unable to load aws config to record fixture (%s): %s", path, err)
+		}
+		fixture = client.NewFixtureRecorder(cfg.Region)
+		t.Cleanup(func() {
+			if err := fixture.Save(path); err != nil {
+				t.Errorf("unable to save fixture (%s): %s", path, err)
+			}
+		})
+	} else {
+		var err error
+		fixture, err = client.LoadFixture(path)
+		if err != nil {
+			t.Fatalf("unable to load fixture: %s", err)
+		}
+		cfg = aws.Config{
+			Region: fixture.Region,
+			Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "AKIDFIXTURE", SecretAccessKey: "fixture"}, nil
+			}),
+		}
+	}
+	cfg.APIOptions = append(cfg.APIOptions, fixture.APIOption())
+
+	conn := client.InitiateClient(cfg)
+	conn.AccountId = AccountId
+	conn.Partition = "aws"
+	cache, err := internalPricing.LoadCache(filepath.Join(t.TempDir(), "pricing.json"))
+	if err != nil {
+		t.Fatalf("unable to create price cache: %s", err)
+	}
+	conn.Estimator = internalPricing.NewEstimator(conn.Pricing, cache)
+	return conn
+}
+
+// Case is a run of a check against a fixture and the summary of the result it
+// is expected to return.
+type Case struct {
+	Name string
+	// Fixture is the path of the fixture, e.g. testdata/IdleDBInstances.json.
+	Fixture string
+	// Configure optionally changes the client before the check runs, e.g. to
+	// set a tag filter.
+	Configure func(conn *client.AWSClient)
+
+	ResourcesEvaluated int
+	ResourcesFlagged   int
+	Status             string
+	// ResourceIds are the resource ids of the findings, in order.
+	ResourceIds             []string
+	EstimatedMonthlySavings float64
+}
+
+// RunCases runs a new check from newCheck with its default parameters against
+// the fixture of every case, and asserts the summary of the result matches the
+// case and every price was resolved. The results are returned in the order of
+// cases for check specific assertions.
+func RunCases(t *testing.T, newCheck func() internalAws.Check, cases []Case) []common.Result {
+	t.Helper()
+
+	results := make([]common.Result, len(cases))
+	for i, c := range cases {
+		conn := NewClient(t, c.Fixture)
+		if c.Configure != nil {
+			c.Configure(&conn)
+		}
+		check := newCheck()
+		result, err := check.Run(context.Background(), conn, check.Metadata().DefaultParameters())
+		if err != nil {
+			t.Fatalf("%s: unexpected error running check: %s", c.Name, err)
+		}
+		if err := conn.Estimator.Err(); err != nil {
+			t.Fatalf("%s: unexpected error resolving prices: %s", c.Name, err)
+		}
+
+		summary := result.Summary()
+		if summary.ResourcesEvaluated != c.ResourcesEvaluated {
+			t.Fatalf("%s: ResourcesEvaluated should be %d, Got %d", c.Name, c.ResourcesEvaluated, summary.ResourcesEvaluated)
+		}
+		if summary.ResourcesFlagged != c.ResourcesFlagged {
+			t.Fatalf("%s: ResourcesFlagged should be %d, Got %d", c.Name, c.ResourcesFlagged, summary.ResourcesFlagged)
+		}
+		if summary.Status != c.Status {
+			t.Fatalf("%s: Status should be %s, Got %s", c.Name, c.Status, summary.Status)
+		}
+		resourceIds := []string{}
+		for _, finding := range summary.Findings {
+			resourceIds = append(resourceIds, finding.ResourceId)
+		}
+		if c.ResourceIds == nil {
+			c.ResourceIds = []string{}
+		}
+		if !reflect.DeepEqual(resourceIds, c.ResourceIds) {
+			t.Fatalf("%s: ResourceIds should be %v, Got %v", c.Name, c.ResourceIds, resourceIds)
+		}
+		if summary.EstimatedMonthlySavings != c.EstimatedMonthlySavings {
+			t.Fatalf("%s: EstimatedMonthlySavings should be %.2f, Got %.2f", c.Name, c.EstimatedMonthlySavings, summary.EstimatedMonthlySavings)
+		}
+		results[i] = result
+	}
+	return results
+}
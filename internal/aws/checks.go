@@ -1,21 +1,112 @@
 package aws
 
 import (
-	"github.com/brittandeyoung/ckia/internal/aws/cost"
-	"github.com/brittandeyoung/ckia/internal/aws/security"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+// Check is implemented by every aws check. Checks register themselves with
+// RegisterCheck from an init function in their package.
+type Check interface {
+	// Metadata returns the static description of the check.
+	Metadata() common.Check
+	// Run executes the check against the account and region of conn using
+	// params resolved from the parameters declared in the metadata. When there
+	// are no resources to evaluate the result has a not_applicable status.
+	Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error)
+}
+
+// CheckFactory returns a new, zero valued instance of a check.
+type CheckFactory func() Check
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]CheckFactory{}
 )
 
-type checkMapping map[string]interface{}
+// RegisterCheck adds a check to the registry. It panics if the check does not
+// provide an id or category, or if another check is already registered with
+// the same id.
+func RegisterCheck(factory CheckFactory) {
+	metadata := factory().Metadata()
+	if metadata.Id == "" {
+		panic("aws: RegisterCheck called with a check missing an id")
+	}
+	if metadata.Category == "" {
+		panic(fmt.Sprintf("aws: check (%s) is missing a category", metadata.Id))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[metadata.Id]; ok {
+		panic(fmt.Sprintf("aws: check (%s) registered twice", metadata.Id))
+	}
+	registry[metadata.Id] = factory
+}
+
+// NewCheck returns a new instance of the check registered with id.
+func NewCheck(id string) (Check, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[id]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// CheckIds returns the ids of all registered checks in sorted order.
+func CheckIds() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	ids := make([]string, 0, len(registry))
+	for id := range registry {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CheckParameters resolves the parameters of every registered check from the
+// checks section of the config file, which is keyed by check short name, e.g.
+// checks.IdleDBInstances.lookbackDays. Checks that are not configured use their
+// defaults. Unknown checks and parameters are an error.
+func CheckParameters(config map[string]interface{}) (map[string]common.Parameters, error) {
+	checks := map[string]common.Check{}
+	for _, id := range CheckIds() {
+		check, _ := NewCheck(id)
+		metadata := check.Metadata()
+		checks[strings.ToLower(metadata.ShortName())] = metadata
+	}
+
+	for name, value := range config {
+		if _, ok := checks[strings.ToLower(name)]; !ok {
+			return nil, fmt.Errorf("unknown check (%s) in checks configuration", name)
+		}
+		if _, ok := value.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("configuration for check (%s) must be a map of parameters", name)
+		}
+	}
 
-func BuildChecksMap() map[string]interface{} {
-	checksMap := checkMapping{
-		// Cost Checks go here
-		cost.IdleDBInstancesCheckId:                new(cost.IdleDBInstancesCheck),
-		cost.IdleLoadBalancersCheckId:              new(cost.IdleLoadBalancersCheck),
-		cost.UnderutilizedEBSVolumesCheckId:        new(cost.UnderutilizedEBSVolumesCheck),
-		cost.UnassociatedElasticIPAddressesCheckId: new(cost.UnassociatedElasticIPAddressesCheck),
-		// Security checks go here
-		security.RootAccountMissingMFACheckId: new(security.RootAccountMissingMFACheck),
+	params := map[string]common.Parameters{}
+	for _, metadata := range checks {
+		var overrides map[string]interface{}
+		for name, value := range config {
+			if strings.EqualFold(name, metadata.ShortName()) {
+				overrides = value.(map[string]interface{})
+			}
+		}
+		resolved, err := metadata.ResolveParameters(overrides)
+		if err != nil {
+			return nil, err
+		}
+		params[metadata.Id] = resolved
 	}
-	return checksMap
+	return params, nil
 }
@@ -1,30 +1,152 @@
-package aws
+package aws_test
 
 import (
-	"reflect"
+	"strings"
 	"testing"
 
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	_ "github.com/brittandeyoung/ckia/internal/aws/cost"
+	_ "github.com/brittandeyoung/ckia/internal/aws/faulttolerance"
+	_ "github.com/brittandeyoung/ckia/internal/aws/performance"
+	_ "github.com/brittandeyoung/ckia/internal/aws/security"
+	_ "github.com/brittandeyoung/ckia/internal/aws/servicelimits"
 	"github.com/brittandeyoung/ckia/internal/common"
 )
 
-func TestChecksMapStructContainsRunMethod(t *testing.T) {
-	checksMap := BuildChecksMap()
-	for k := range checksMap {
-		st := reflect.TypeOf(checksMap[k])
-		_, ok := st.MethodByName(common.MethodNameRun)
+func TestRegisteredChecksMetadata(t *testing.T) {
+	ids := internalAws.CheckIds()
+	if len(ids) == 0 {
+		t.Fatal("No checks registered.")
+	}
+	categories := []string{
+		common.CategoryCostOptimization,
+		common.CategoryPerformance,
+		common.CategorySecurity,
+		common.CategoryFaultTolerance,
+		common.CategoryServiceLimits,
+	}
+	for _, id := range ids {
+		check, ok := internalAws.NewCheck(id)
 		if !ok {
-			t.Fatalf("Check: (%s) is missing Run() method.", k)
+			t.Fatalf("Check: (%s) is registered but could not be created.", id)
+		}
+		metadata := check.Metadata()
+		if metadata.Id != id {
+			t.Fatalf("Check: (%s) is registered under a different id than its metadata (%s).", id, metadata.Id)
+		}
+		if !common.StringSliceContains(categories, metadata.Category) {
+			t.Fatalf("Check: (%s) has an unknown category (%s).", id, metadata.Category)
+		}
+		if !common.StringSliceContains([]string{common.SeverityLow, common.SeverityMedium, common.SeverityHigh, common.SeverityCritical}, metadata.Severity) {
+			t.Fatalf("Check: (%s) has an unknown severity (%s).", id, metadata.Severity)
+		}
+		if metadata.Name == "" || metadata.Description == "" || metadata.Criteria == "" || metadata.RecommendedAction == "" {
+			t.Fatalf("Check: (%s) is missing required metadata.", id)
+		}
+		if len(metadata.Permissions) == 0 {
+			t.Fatalf("Check: (%s) does not declare the IAM actions it calls.", id)
+		}
+		for _, permission := range metadata.Permissions {
+			if service, action, ok := strings.Cut(permission, ":"); !ok || service == "" || action == "" {
+				t.Fatalf("Check: (%s) declares an invalid IAM action (%s).", id, permission)
+			}
 		}
 	}
 }
 
-func TestChecksMapStructContainsListMethod(t *testing.T) {
-	checksMap := BuildChecksMap()
-	for k := range checksMap {
-		st := reflect.TypeOf(checksMap[k])
-		_, ok := st.MethodByName(common.MethodNameList)
-		if !ok {
-			t.Fatalf("Check: (%s) is missing List() method.", k)
+func TestNewCheckUnknownId(t *testing.T) {
+	if _, ok := internalAws.NewCheck("ckia:aws:cost:DoesNotExist"); ok {
+		t.Fatal("NewCheck returned a check for an unknown id.")
+	}
+}
+
+func TestCheckParameters(t *testing.T) {
+	config := map[string]interface{}{
+		"idledbinstances": map[string]interface{}{
+			"lookbackdays": 30,
+		},
+	}
+
+	params, err := internalAws.CheckParameters(config)
+	if err != nil {
+		t.Fatalf("Unexpected error resolving parameters: %s", err)
+	}
+
+	idle := params["ckia:aws:cost:IdleDBInstances"]
+	if idle.Int("lookbackDays") != 30 {
+		t.Fatalf("lookbackDays should be 30, Got %d", idle.Int("lookbackDays"))
+	}
+	if idle.Int("idleDays") != 7 {
+		t.Fatalf("idleDays should default to 7, Got %d", idle.Int("idleDays"))
+	}
+
+	for _, id := range internalAws.CheckIds() {
+		if _, ok := params[id]; !ok {
+			t.Fatalf("Check: (%s) has no resolved parameters.", id)
+		}
+	}
+}
+
+func TestCheckParametersUnknownCheck(t *testing.T) {
+	config := map[string]interface{}{
+		"DoesNotExist": map[string]interface{}{},
+	}
+
+	if _, err := internalAws.CheckParameters(config); err == nil {
+		t.Fatal("Expected an error for an unknown check.")
+	}
+}
+
+func TestCheckParametersUnknownParameter(t *testing.T) {
+	config := map[string]interface{}{
+		"IdleLoadBalancers": map[string]interface{}{
+			"doesNotExist": 1,
+		},
+	}
+
+	if _, err := internalAws.CheckParameters(config); err == nil {
+		t.Fatal("Expected an error for an unknown parameter.")
+	}
+}
+
+func TestCheckParametersFractionalDays(t *testing.T) {
+	config := map[string]interface{}{
+		"IdleDBInstances": map[string]interface{}{
+			"lookbackDays": 7.5,
+		},
+	}
+
+	if _, err := internalAws.CheckParameters(config); err == nil {
+		t.Fatal("Expected an error for a fractional number of days.")
+	}
+}
+
+func TestCheckParametersIdleDaysAboveLookback(t *testing.T) {
+	config := map[string]interface{}{
+		"IdleDBInstances": map[string]interface{}{
+			"lookbackDays": 7,
+			"idleDays":     10,
+		},
+	}
+
+	if _, err := internalAws.CheckParameters(config); err == nil {
+		t.Fatal("Expected an error for idleDays greater than lookbackDays.")
+	}
+}
+
+func TestCheckParametersZeroDays(t *testing.T) {
+	for check, parameter := range map[string]string{
+		"IdleLoadBalancers":           "lookbackDays",
+		"HighUtilizationEC2Instances": "minDaysAboveThreshold",
+	} {
+		config := map[string]interface{}{
+			check: map[string]interface{}{
+				parameter: 0,
+			},
+		}
+
+		if _, err := internalAws.CheckParameters(config); err == nil {
+			t.Fatalf("Expected an error for 0 %s of %s.", parameter, check)
 		}
 	}
 }
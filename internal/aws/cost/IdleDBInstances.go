@@ -2,13 +2,15 @@ package cost
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
 	"github.com/brittandeyoung/ckia/internal/client"
 	"github.com/brittandeyoung/ckia/internal/common"
 )
@@ -17,43 +19,83 @@ const (
 	IdleDBInstancesCheckId                  = "ckia:aws:cost:IdleDBInstances"
 	IdleDBInstancesCheckName                = "RDS Idle DB Instances"
 	IdleDBInstancesCheckDescription         = "Checks the configuration of your Amazon Relational Database Service (Amazon RDS) for any DB instances that appear to be idle. If a DB instance has not had a connection for a prolonged period of time, you can delete the instance to reduce costs. If persistent storage is needed for data on the instance, you can use lower-cost options such as taking and retaining a DB snapshot. Manually created DB snapshots are retained until you delete them."
-	IdleDBInstancesCheckCriteria            = "Any RDS DB instance that has not had a connection in the last 7 days is considered idle."
+	IdleDBInstancesCheckCriteria            = "Any RDS DB instance that has not had a connection in the last %d days is considered idle."
 	IdleDBInstancesCheckRecommendedAction   = "Consider taking a snapshot of the idle DB instance and then either stopping it or deleting it. Stopping the DB instance removes some of the costs for it, but does not remove storage costs. A stopped instance keeps all automated backups based upon the configured retention period. Stopping a DB instance usually incurs additional costs when compared to deleting the instance and then retaining only the final snapshot."
 	IdleDBInstancesCheckAdditionalResources = "See comparable AWS Trusted advisor check: https://docs.aws.amazon.com/awssupport/latest/user/cost-optimization-checks.html#amazon-rds-idle-dbs-instances"
+
+	IdleDBInstancesParameterLookbackDays = "lookbackDays"
+	IdleDBInstancesParameterIdleDays     = "idleDays"
+
+	IdleDBInstanceReasonNoConnections = "no connections in the last %d days"
 )
 
 type IdleDBInstance struct {
-	Region                  string `json:"region"`
-	DBInstanceName          string `json:"dbInstanceName"`
-	MultiAZ                 bool   `json:"multiAZ"`
-	InstanceType            string `json:"instanceType"`
-	StorageProvisionedInGB  int    `json:"storageProvisionedInGB"`
-	DaysSinceLastConnection int    `json:"daysSinceLastConnection"`
-	EstimatedMonthlySavings int    `json:"estimatedMonthlySavings"`
+	AccountId               string            `json:"accountId"`
+	AccountName             string            `json:"accountName,omitempty"`
+	Region                  string            `json:"region"`
+	DBInstanceName          string            `json:"dbInstanceName"`
+	MultiAZ                 bool              `json:"multiAZ"`
+	InstanceType            string            `json:"instanceType"`
+	StorageProvisionedInGB  int               `json:"storageProvisionedInGB"`
+	DaysSinceLastConnection int               `json:"daysSinceLastConnection"`
+	EstimatedMonthlySavings float64           `json:"estimatedMonthlySavings"`
+	Tags                    map[string]string `json:"tags,omitempty"`
 }
 
 type IdleDBInstancesCheck struct {
 	common.Check
+	common.CheckResult
 	IdleDBInstances []IdleDBInstance `json:"idleDBInstances"`
 }
 
-func (v *IdleDBInstancesCheck) List() *IdleDBInstancesCheck {
-	v.Check = common.Check{
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(IdleDBInstancesCheck) })
+}
+
+func (v *IdleDBInstancesCheck) Metadata() common.Check {
+	check := common.Check{
 		Id:                  IdleDBInstancesCheckId,
+		Category:            common.CategoryCostOptimization,
+		Severity:            common.SeverityMedium,
 		Name:                IdleDBInstancesCheckName,
 		Description:         IdleDBInstancesCheckDescription,
 		Criteria:            IdleDBInstancesCheckCriteria,
 		RecommendedAction:   IdleDBInstancesCheckRecommendedAction,
 		AdditionalResources: IdleDBInstancesCheckAdditionalResources,
+		Permissions: []string{
+			"rds:DescribeDBInstances",
+			"cloudwatch:GetMetricData",
+			"pricing:GetProducts",
+		},
+		Parameters: []common.Parameter{
+			{
+				Name:        IdleDBInstancesParameterLookbackDays,
+				Description: "The number of days of connection metrics to evaluate.",
+				Default:     14,
+				Integer:     true,
+				Min:         1,
+			},
+			{
+				Name:        IdleDBInstancesParameterIdleDays,
+				Description: "The number of days without a connection before a DB instance is considered idle.",
+				Default:     7,
+				Integer:     true,
+				Min:         1,
+				AtMost:      IdleDBInstancesParameterLookbackDays,
+			},
+		},
 	}
-
-	return v
+	return check.Describe(v.EffectiveParameters, func(check *common.Check, params common.Parameters) {
+		check.Criteria = fmt.Sprintf(IdleDBInstancesCheckCriteria, params.Int(IdleDBInstancesParameterIdleDays))
+	})
 }
 
-func (v *IdleDBInstancesCheck) Run(ctx context.Context, conn client.AWSClient) (*IdleDBInstancesCheck, error) {
-	v = v.List()
+func (v *IdleDBInstancesCheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.CheckResult = common.NewCheckResult(conn.AccountId, conn.Region, params)
+	v.Check = v.Metadata()
 
-	currentTime := time.Now()
+	lookbackDays := params.Int(IdleDBInstancesParameterLookbackDays)
+	idleDays := params.Int(IdleDBInstancesParameterIdleDays)
 
 	in := &rds.DescribeDBInstancesInput{}
 	var dbInstances []rdsTypes.DBInstance
@@ -66,111 +108,88 @@ func (v *IdleDBInstancesCheck) Run(ctx context.Context, conn client.AWSClient) (
 		if err != nil {
 			return nil, err
 		}
-		dbInstances = append(dbInstances, output.DBInstances...)
+		for _, dbInstance := range output.DBInstances {
+			if conn.TagFilter.Match(client.RDSTags(dbInstance.TagList)) {
+				dbInstances = append(dbInstances, dbInstance)
+			}
+		}
 
 	}
 
-	if len(dbInstances) == 0 {
-		return nil, nil
-	}
+	v.ResourcesEvaluated = len(dbInstances)
 
-	var idleDBInstances []IdleDBInstance
+	queries := make([]client.MetricQuery, 0, len(dbInstances))
 	for _, dbInstance := range dbInstances {
-
-		metrics, err := conn.Cloudwatch.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
-			MetricName: aws.String("DatabaseConnections"),
-			Period:     aws.Int32(3600),
-			Namespace:  aws.String("AWS/RDS"),
-			Statistics: []types.Statistic{types.StatisticAverage},
+		queries = append(queries, client.MetricQuery{
+			Namespace:  "AWS/RDS",
+			MetricName: "DatabaseConnections",
 			Dimensions: []types.Dimension{
 				{
 					Name:  aws.String("DBInstanceIdentifier"),
 					Value: dbInstance.DBInstanceIdentifier,
 				},
 			},
-			StartTime: aws.Time(currentTime.AddDate(0, 0, -14)),
-			EndTime:   aws.Time(currentTime),
+			Stat:         types.StatisticAverage,
+			Period:       3600,
+			LookbackDays: lookbackDays,
 		})
+	}
+	metrics, err := conn.Metrics.Datapoints(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	var idleDBInstances []IdleDBInstance
+	for i, dbInstance := range dbInstances {
 
 		var idleDBInstance IdleDBInstance
-		daysSinceConnection, connectionFound := expandConnections(metrics.Datapoints)
+		daysSinceConnection, connectionFound := expandConnections(metrics[i], lookbackDays, idleDays)
 
 		if !connectionFound {
-			// pricingSvc := pricing.NewFromConfig(cfg)
-			// filters := []pricingtypes.Filter{
-			// 	{
-			// 		Field: aws.String("InstanceType"),
-			// 		Type:  "TERM_MATCH",
-			// 		Value: dbInstance.DBInstanceClass,
-			// 	},
-			// 	// These two seam to not match what the pricing API is expecting
-			// 	{
-			// 		Field: aws.String("storage"),
-			// 		Type:  "TERM_MATCH",
-			// 		Value: dbInstance.StorageType,
-			// 	},
-			// 	{
-			// 		Field: aws.String("databaseEngine"),
-			// 		Type:  "TERM_MATCH",
-			// 		Value: dbInstance.Engine,
-			// 	},
-			// 	{
-			// 		Field: aws.String("deploymentOption"),
-			// 		Type:  "TERM_MATCH",
-			// 		Value: aws.String("Single-AZ"),
-			// 	},
-			// 	{
-			// 		Field: aws.String("termType"),
-			// 		Type:  "TERM_MATCH",
-			// 		Value: aws.String("OnDemand"),
-			// 	},
-			// 	{
-			// 		Field: aws.String("regionCode"),
-			// 		Type:  "TERM_MATCH",
-			// 		Value: &cfg.Region,
-			// 	},
-			// 	{
-			// 		Field: aws.String("purchaseOption"),
-			// 		Type:  "TERM_MATCH",
-			// 		Value: aws.String("No Upfront"),
-			// 	},
-			// }
-
-			// pricingIn := &pricing.GetProductsInput{
-			// 	ServiceCode: aws.String("AmazonRDS"),
-			// 	Filters:     filters,
-			// }
-			// pricingData, err := pricingSvc.GetProducts(ctx, pricingIn)
-
-			// if err != nil {
-			// 	return IdleDBInstancesCheck{}
-			// }
-
 			idleDBInstance.DBInstanceName = aws.ToString(dbInstance.DBInstanceIdentifier)
+			idleDBInstance.AccountId = conn.AccountId
+			idleDBInstance.AccountName = conn.AccountName
 			idleDBInstance.Region = conn.Region
 			idleDBInstance.DaysSinceLastConnection = daysSinceConnection
 			idleDBInstance.InstanceType = aws.ToString(dbInstance.DBInstanceClass)
 			idleDBInstance.MultiAZ = dbInstance.MultiAZ
 			idleDBInstance.StorageProvisionedInGB = int(dbInstance.AllocatedStorage)
-			// Still trying to figure out how to get the proper on demand pricing via the API
-			// idleDBInstance.EstimatedMonthlySavings = 0
+			idleDBInstance.Tags = client.RDSTags(dbInstance.TagList)
+			idleDBInstance.EstimatedMonthlySavings = common.RoundCents(
+				conn.Estimator.RDSInstanceMonthlyCost(ctx, conn.Region, aws.ToString(dbInstance.DBInstanceClass), aws.ToString(dbInstance.Engine), aws.ToString(dbInstance.LicenseModel), dbInstance.MultiAZ) +
+					conn.Estimator.RDSStorageMonthlyCost(ctx, conn.Region, aws.ToString(dbInstance.StorageType), int(dbInstance.AllocatedStorage), dbInstance.MultiAZ))
 			idleDBInstances = append(idleDBInstances, idleDBInstance)
+			v.AddFinding(expandIdleDBInstanceFinding(idleDBInstance, aws.ToString(dbInstance.DBInstanceArn), idleDays))
 		}
 
 	}
 
 	v.IdleDBInstances = idleDBInstances
+	v.Evaluate(v.Severity)
 	return v, nil
 }
 
-func expandConnections(dataPoints []types.Datapoint) (int, bool) {
+func expandIdleDBInstanceFinding(idleDBInstance IdleDBInstance, arn string, idleDays int) common.Finding {
+	return common.Finding{
+		ResourceId:              idleDBInstance.DBInstanceName,
+		ResourceArn:             arn,
+		AccountId:               idleDBInstance.AccountId,
+		AccountName:             idleDBInstance.AccountName,
+		Region:                  idleDBInstance.Region,
+		Reason:                  fmt.Sprintf(IdleDBInstanceReasonNoConnections, idleDays),
+		EstimatedMonthlySavings: idleDBInstance.EstimatedMonthlySavings,
+		Tags:                    idleDBInstance.Tags,
+		Metadata: map[string]string{
+			"instanceType":            idleDBInstance.InstanceType,
+			"multiAZ":                 strconv.FormatBool(idleDBInstance.MultiAZ),
+			"daysSinceLastConnection": strconv.Itoa(idleDBInstance.DaysSinceLastConnection),
+		},
+	}
+}
+
+func expandConnections(dataPoints []types.Datapoint, lookbackDays int, idleDays int) (int, bool) {
 	connectionFound := false
-	var daysSinceConnection float64
-	daysSinceConnection = 14
+	daysSinceConnection := float64(lookbackDays)
 	for _, dataPoint := range dataPoints {
 		if aws.ToFloat64(dataPoint.Average) != 0 {
 			duration := time.Now().Sub(aws.ToTime(dataPoint.Timestamp))
@@ -178,7 +197,7 @@ func expandConnections(dataPoints []types.Datapoint) (int, bool) {
 				daysSinceConnection = duration.Hours() / 24
 			}
 
-			if duration.Hours()/24 <= 7 {
+			if duration.Hours()/24 <= float64(idleDays) {
 				connectionFound = true
 			}
 		}
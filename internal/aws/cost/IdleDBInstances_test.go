@@ -1,11 +1,16 @@
 package cost
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/create"
 )
 
 func TestExpandConnections_basic(t *testing.T) {
@@ -47,7 +52,7 @@ func TestExpandConnections_basic(t *testing.T) {
 		},
 	}
 
-	daysSinceConnection, connectionFound := expandConnections(dataPoints)
+	daysSinceConnection, connectionFound := expandConnections(dataPoints, 14, 7)
 
 	if connectionFound || daysSinceConnection != 14 {
 		t.Fatal(`Connection found when no connection present`)
@@ -94,7 +99,7 @@ func TestExpandConnections_withConnectionsInSevenDays(t *testing.T) {
 		},
 	}
 
-	daysSinceConnection, connectionFound := expandConnections(dataPoints)
+	daysSinceConnection, connectionFound := expandConnections(dataPoints, 14, 7)
 
 	if !connectionFound {
 		t.Fatal(`Connection not found when connection is present`)
@@ -144,7 +149,7 @@ func TestExpandConnections_withConnectionsAfterSevenDays(t *testing.T) {
 		},
 	}
 
-	daysSinceConnection, connectionFound := expandConnections(dataPoints)
+	daysSinceConnection, connectionFound := expandConnections(dataPoints, 14, 7)
 
 	if connectionFound {
 		t.Fatal(`Connection reported within the last 7 days when not present.`)
@@ -154,3 +159,54 @@ func TestExpandConnections_withConnectionsAfterSevenDays(t *testing.T) {
 		t.Fatalf(`Days Since Connetion should be 8, Got %d`, daysSinceConnection)
 	}
 }
+
+func TestExpandConnections_withIdleDaysParameter(t *testing.T) {
+	dataPoints := []types.Datapoint{
+		{
+			Timestamp: aws.Time(time.Now().Add(time.Hour * -192)),
+			Average:   aws.Float64(1.0),
+			Unit:      "Count",
+		},
+	}
+
+	daysSinceConnection, connectionFound := expandConnections(dataPoints, 30, 10)
+
+	if !connectionFound {
+		t.Fatal(`Connection not found within the configured idle days.`)
+	}
+
+	if daysSinceConnection != 8 {
+		t.Fatalf(`Days Since Connetion should be 8, Got %d`, daysSinceConnection)
+	}
+}
+
+func TestIdleDBInstancesRun_fixture(t *testing.T) {
+	results := awstest.RunCases(t, func() internalAws.Check { return new(IdleDBInstancesCheck) }, []awstest.Case{
+		{
+			Name:                    "idle instances",
+			Fixture:                 "testdata/IdleDBInstances.json",
+			ResourcesEvaluated:      2,
+			ResourcesFlagged:        2,
+			Status:                  common.StatusWarning,
+			ResourceIds:             []string{"reporting", "legacy"},
+			EstimatedMonthlySavings: 29.42,
+		},
+	})
+	if results[0].Summary().Findings[0].EstimatedMonthlySavings != 14.71 {
+		create.TestFailureAttribute(t, "EstimatedMonthlySavings", "14.71")
+	}
+}
+
+func TestIdleDBInstancesMetadata_criteria(t *testing.T) {
+	check := new(IdleDBInstancesCheck)
+	if criteria := check.Metadata().Criteria; !strings.Contains(criteria, "last 7 days") {
+		create.TestFailureAttribute(t, "Criteria", "last 7 days")
+	}
+
+	params := check.Metadata().DefaultParameters()
+	params[IdleDBInstancesParameterIdleDays] = 10
+	check.CheckResult = common.NewCheckResult("", "", params)
+	if criteria := check.Metadata().Criteria; !strings.Contains(criteria, "last 10 days") {
+		create.TestFailureAttribute(t, "Criteria", "last 10 days")
+	}
+}
@@ -2,13 +2,14 @@ package cost
 
 import (
 	"context"
-	"time"
+	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	lbTypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
 	"github.com/brittandeyoung/ckia/internal/client"
 	"github.com/brittandeyoung/ckia/internal/common"
 )
@@ -16,45 +17,94 @@ import (
 const (
 	IdleLoadBalancersCheckId                  = "ckia:aws:cost:IdleLoadBalancers"
 	IdleLoadBalancersCheckName                = "Idle Load Balancers"
-	IdleLoadBalancersCheckDescription         = "Checks your Elastic Load Balancing configuration for load balancers that are idle. Any load balancer that is configured accrues charges. If a load balancer has no associated back-end instances, or if network traffic is severely limited, the load balancer is not being used effectively. This check currently only checks for Classic Load Balancer type within ELB service. It does not include other ELB types (Application Load Balancer, Network Load Balancer)"
-	IdleLoadBalancersCheckCriteria            = "A load balancer has no active back-end instances. A load balancer has no healthy back-end instances. A load balancer has had less than 100 requests per day for the last 7 days."
+	IdleLoadBalancersCheckDescription         = "Checks your Elastic Load Balancing configuration for load balancers that are idle. Any load balancer that is configured accrues charges. If a load balancer has no associated back-end instances, or if network traffic is severely limited, the load balancer is not being used effectively. This check covers Application, Network and Gateway Load Balancers. It does not include Classic Load Balancers."
+	IdleLoadBalancersCheckCriteria            = "A load balancer has no active back-end instances. A load balancer has no healthy back-end instances. A load balancer has had no more than %g requests per day, or new flows per day for network and gateway load balancers, for the last %d days."
 	IdleLoadBalancersCheckRecommendedAction   = "If your load balancer has no active back-end instances, consider registering instances or deleting your load balancer. If your load balancer has no healthy back-end instances, troubleshoot why they are un healthy or evaluate for removal. If your load balancer has had a low request count, consider deleting your load balancer. See Delete Your Load Balancer."
 	IdleLoadBalancersCheckAdditionalResources = "See comparable AWS Trusted advisor check: https://docs.aws.amazon.com/awssupport/latest/user/cost-optimization-checks.html#idle-load-balancers"
 
 	IdleLoadBalancerReasonNoActiveInstances  = "no active back-end instances"
 	IdleLoadBalancerReasonNoHealthyInstances = "no healthy back-end instances"
 	IdleLoadBalancerReasonLowRequestCount    = "low request count"
+
+	IdleLoadBalancersParameterLookbackDays      = "lookbackDays"
+	IdleLoadBalancersParameterMinRequestsPerDay = "minRequestsPerDay"
 )
 
+// loadBalancerTrafficMetrics are the CloudWatch namespace and metric counting
+// the daily traffic of each load balancer type. Network and gateway load
+// balancers do not publish RequestCount, so their new flows are counted.
+var loadBalancerTrafficMetrics = map[lbTypes.LoadBalancerTypeEnum]struct {
+	namespace  string
+	metricName string
+}{
+	lbTypes.LoadBalancerTypeEnumApplication: {"AWS/ApplicationELB", "RequestCount"},
+	lbTypes.LoadBalancerTypeEnumNetwork:     {"AWS/NetworkELB", "NewFlowCount"},
+	lbTypes.LoadBalancerTypeEnumGateway:     {"AWS/GatewayELB", "NewFlowCount"},
+}
+
 type IdleLoadBalancer struct {
-	Region                  string `json:"region"`
-	LoadBalancerName        string `json:"loadBalancerName"`
-	Reason                  string `json:"reason"`
-	EstimatedMonthlySavings int    `json:"estimatedMonthlySavings"`
+	AccountId               string            `json:"accountId"`
+	AccountName             string            `json:"accountName,omitempty"`
+	Region                  string            `json:"region"`
+	LoadBalancerName        string            `json:"loadBalancerName"`
+	LoadBalancerType        string            `json:"loadBalancerType"`
+	Reason                  string            `json:"reason"`
+	EstimatedMonthlySavings float64           `json:"estimatedMonthlySavings"`
+	Tags                    map[string]string `json:"tags,omitempty"`
 }
 
 type IdleLoadBalancersCheck struct {
 	common.Check
+	common.CheckResult
 	IdleLoadBalancers []IdleLoadBalancer `json:"idleLoadBalancers"`
 }
 
-func (v *IdleLoadBalancersCheck) List() *IdleLoadBalancersCheck {
-	v.Check = common.Check{
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(IdleLoadBalancersCheck) })
+}
+
+func (v *IdleLoadBalancersCheck) Metadata() common.Check {
+	check := common.Check{
 		Id:                  IdleLoadBalancersCheckId,
+		Category:            common.CategoryCostOptimization,
+		Severity:            common.SeverityLow,
 		Name:                IdleLoadBalancersCheckName,
 		Description:         IdleLoadBalancersCheckDescription,
 		Criteria:            IdleLoadBalancersCheckCriteria,
 		RecommendedAction:   IdleLoadBalancersCheckRecommendedAction,
 		AdditionalResources: IdleLoadBalancersCheckAdditionalResources,
+		Permissions: []string{
+			"elasticloadbalancing:DescribeLoadBalancers",
+			"elasticloadbalancing:DescribeTargetGroups",
+			"elasticloadbalancing:DescribeTags",
+			"elasticloadbalancing:DescribeTargetHealth",
+			"cloudwatch:GetMetricData",
+			"pricing:GetProducts",
+		},
+		Parameters: []common.Parameter{
+			{
+				Name:        IdleLoadBalancersParameterLookbackDays,
+				Description: "The number of days of request metrics to evaluate.",
+				Default:     7,
+				Integer:     true,
+				Min:         1,
+			},
+			{
+				Name:        IdleLoadBalancersParameterMinRequestsPerDay,
+				Description: "A load balancer with no day above this many requests, or new flows for network and gateway load balancers, is considered idle.",
+				Default:     100,
+			},
+		},
 	}
-
-	return v
+	return check.Describe(v.EffectiveParameters, func(check *common.Check, params common.Parameters) {
+		check.Criteria = fmt.Sprintf(IdleLoadBalancersCheckCriteria, params.Float(IdleLoadBalancersParameterMinRequestsPerDay), params.Int(IdleLoadBalancersParameterLookbackDays))
+	})
 }
 
-func (v *IdleLoadBalancersCheck) Run(ctx context.Context, conn client.AWSClient) (*IdleLoadBalancersCheck, error) {
-	v = v.List()
+func (v *IdleLoadBalancersCheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.CheckResult = common.NewCheckResult(conn.AccountId, conn.Region, params)
+	v.Check = v.Metadata()
 
-	currentTime := time.Now()
 	var loadBalancers []lbTypes.LoadBalancer
 	in := &elasticloadbalancingv2.DescribeLoadBalancersInput{}
 
@@ -72,12 +122,29 @@ func (v *IdleLoadBalancersCheck) Run(ctx context.Context, conn client.AWSClient)
 
 	}
 
-	if len(loadBalancers) == 0 {
-		return nil, nil
+	// Load balancers are described without their tags, which are needed to
+	// filter them and to report them on findings.
+	arns := make([]string, 0, len(loadBalancers))
+	for _, lb := range loadBalancers {
+		arns = append(arns, aws.ToString(lb.LoadBalancerArn))
 	}
-
-	var idleLoadBalancers []IdleLoadBalancer
+	tags, err := client.ELBv2Tags(ctx, conn.ELBv2, arns)
+	if err != nil {
+		return nil, err
+	}
+	filtered := loadBalancers[:0]
 	for _, lb := range loadBalancers {
+		if conn.TagFilter.Match(tags[aws.ToString(lb.LoadBalancerArn)]) {
+			filtered = append(filtered, lb)
+		}
+	}
+	loadBalancers = filtered
+
+	v.ResourcesEvaluated = len(loadBalancers)
+
+	idle := make([]IdleLoadBalancer, len(loadBalancers))
+	isIdle := make([]bool, len(loadBalancers))
+	for i, lb := range loadBalancers {
 
 		targetGroups, err := conn.ELBv2.DescribeTargetGroups(ctx, &elasticloadbalancingv2.DescribeTargetGroupsInput{
 			LoadBalancerArn: lb.LoadBalancerArn,
@@ -107,44 +174,76 @@ func (v *IdleLoadBalancersCheck) Run(ctx context.Context, conn client.AWSClient)
 				}
 			}
 		}
+		idle[i], isIdle[i] = idleLoadBalancer, lbIsIdle
+	}
 
-		if !lbIsIdle {
-			metrics, err := conn.Cloudwatch.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
-				MetricName: aws.String("RequestCount"),
-				Period:     aws.Int32(3600),
-				Namespace:  aws.String("AWS/ApplicationELB"),
-				Statistics: []types.Statistic{types.StatisticAverage},
-				Dimensions: []types.Dimension{
-					{
-						Name:  aws.String("LoadBalancer"),
-						Value: lb.LoadBalancerName,
-					},
-				},
-				StartTime: aws.Time(currentTime.AddDate(0, 0, -7)),
-				EndTime:   aws.Time(currentTime),
-			})
-
-			if err != nil {
-				return nil, err
-			}
-
-			idleLoadBalancer, lbIsIdle = expandLowRequestCountLoadBalancer(idleLoadBalancer, metrics.Datapoints)
+	// The traffic is only needed for load balancers with healthy targets, and
+	// is not evaluated for types without a traffic metric.
+	var active []int
+	var queries []client.MetricQuery
+	for i, lb := range loadBalancers {
+		metric, ok := loadBalancerTrafficMetrics[lb.Type]
+		if isIdle[i] || !ok {
+			continue
 		}
+		active = append(active, i)
+		queries = append(queries, client.MetricQuery{
+			Namespace:  metric.namespace,
+			MetricName: metric.metricName,
+			Dimensions: []types.Dimension{
+				{
+					Name:  aws.String("LoadBalancer"),
+					Value: aws.String(loadBalancerDimension(aws.ToString(lb.LoadBalancerArn))),
+				},
+			},
+			Stat:         types.StatisticSum,
+			Period:       86400,
+			LookbackDays: params.Int(IdleLoadBalancersParameterLookbackDays),
+		})
+	}
+	metrics, err := conn.Metrics.Datapoints(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range active {
+		idle[i], isIdle[i] = expandLowRequestCountLoadBalancer(idle[i], metrics[j], params.Float(IdleLoadBalancersParameterMinRequestsPerDay))
+	}
 
-		if lbIsIdle {
+	var idleLoadBalancers []IdleLoadBalancer
+	for i, lb := range loadBalancers {
+		if isIdle[i] {
+			idleLoadBalancer := idle[i]
 			idleLoadBalancer.LoadBalancerName = aws.ToString(lb.LoadBalancerName)
+			idleLoadBalancer.LoadBalancerType = string(lb.Type)
+			idleLoadBalancer.AccountId = conn.AccountId
+			idleLoadBalancer.AccountName = conn.AccountName
 			idleLoadBalancer.Region = conn.Region
-			// Still trying to figure out how to get the proper on demand pricing via the API
-			// idleLoadBalancer.EstimatedMonthlySavings = 0
+			idleLoadBalancer.Tags = tags[aws.ToString(lb.LoadBalancerArn)]
+			idleLoadBalancer.EstimatedMonthlySavings = conn.Estimator.LoadBalancerMonthlyCost(ctx, conn.Region, idleLoadBalancer.LoadBalancerType)
 			idleLoadBalancers = append(idleLoadBalancers, idleLoadBalancer)
+			v.AddFinding(expandIdleLoadBalancerFinding(idleLoadBalancer, aws.ToString(lb.LoadBalancerArn)))
 		}
 
 	}
 
 	v.IdleLoadBalancers = idleLoadBalancers
+	v.Evaluate(v.Severity)
 	return v, nil
 }
 
+func expandIdleLoadBalancerFinding(idleLoadBalancer IdleLoadBalancer, arn string) common.Finding {
+	return common.Finding{
+		ResourceId:              idleLoadBalancer.LoadBalancerName,
+		ResourceArn:             arn,
+		AccountId:               idleLoadBalancer.AccountId,
+		AccountName:             idleLoadBalancer.AccountName,
+		Region:                  idleLoadBalancer.Region,
+		Reason:                  idleLoadBalancer.Reason,
+		EstimatedMonthlySavings: idleLoadBalancer.EstimatedMonthlySavings,
+		Tags:                    idleLoadBalancer.Tags,
+	}
+}
+
 func expandInactiveLoadBalancer(idleLoadBalancer IdleLoadBalancer, descriptions []lbTypes.TargetHealthDescription) (IdleLoadBalancer, bool) {
 	if len(descriptions) == 0 {
 		idleLoadBalancer.Reason = IdleLoadBalancerReasonNoActiveInstances
@@ -166,9 +265,19 @@ func expandUnhealthyLoadBalancer(idleLoadBalancer IdleLoadBalancer, descriptions
 	return idleLoadBalancer, true
 }
 
-func expandLowRequestCountLoadBalancer(idleLoadBalancer IdleLoadBalancer, dataPoints []types.Datapoint) (IdleLoadBalancer, bool) {
+// loadBalancerDimension returns the value of the LoadBalancer metric dimension
+// for a load balancer, which is the final portion of its arn, e.g.
+// app/my-load-balancer/50dc6c495c0c9188.
+func loadBalancerDimension(arn string) string {
+	if i := strings.Index(arn, ":loadbalancer/"); i >= 0 {
+		return arn[i+len(":loadbalancer/"):]
+	}
+	return arn
+}
+
+func expandLowRequestCountLoadBalancer(idleLoadBalancer IdleLoadBalancer, dataPoints []types.Datapoint, minRequestsPerDay float64) (IdleLoadBalancer, bool) {
 	for _, dataPoint := range dataPoints {
-		if aws.ToFloat64(dataPoint.Sum) > 100 {
+		if aws.ToFloat64(dataPoint.Sum) > minRequestsPerDay {
 			return idleLoadBalancer, false
 		}
 	}
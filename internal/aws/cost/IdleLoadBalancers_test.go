@@ -1,11 +1,19 @@
 package cost
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
 	"github.com/brittandeyoung/ckia/internal/create"
 )
 
@@ -14,7 +22,7 @@ func TestExpandInactiveLoadBalancer_basic(t *testing.T) {
 	descriptions := []types.TargetHealthDescription{}
 	lb, lbIsIdle := expandInactiveLoadBalancer(idleLoadBalancer, descriptions)
 
-	if lb == (IdleLoadBalancer{}) {
+	if reflect.DeepEqual(lb, IdleLoadBalancer{}) {
 		create.TestFailureEmptyStruct(t)
 	}
 
@@ -48,7 +56,7 @@ func TestExpandUnhealthyLoadBalancer_basic(t *testing.T) {
 	}
 	lb, lbIsIdle := expandUnhealthyLoadBalancer(idleLoadBalancer, descriptions)
 
-	if lb == (IdleLoadBalancer{}) {
+	if reflect.DeepEqual(lb, IdleLoadBalancer{}) {
 		create.TestFailureEmptyStruct(t)
 	}
 
@@ -75,9 +83,9 @@ func TestExpandLowRequestCountLoadBalancer_basic(t *testing.T) {
 		},
 	}
 
-	lb, lbIsIdle := expandLowRequestCountLoadBalancer(idleLoadBalancer, dataPoints)
+	lb, lbIsIdle := expandLowRequestCountLoadBalancer(idleLoadBalancer, dataPoints, 100)
 
-	if lb == (IdleLoadBalancer{}) {
+	if reflect.DeepEqual(lb, IdleLoadBalancer{}) {
 		create.TestFailureEmptyStruct(t)
 	}
 
@@ -104,9 +112,9 @@ func TestExpandLowRequestCountLoadBalancer_notIdle(t *testing.T) {
 		},
 	}
 
-	lb, lbIsIdle := expandLowRequestCountLoadBalancer(idleLoadBalancer, dataPoints)
+	lb, lbIsIdle := expandLowRequestCountLoadBalancer(idleLoadBalancer, dataPoints, 100)
 
-	if lb != (IdleLoadBalancer{}) {
+	if !reflect.DeepEqual(lb, IdleLoadBalancer{}) {
 		create.TestFailureNonEmptyStruct(t)
 	}
 
@@ -114,3 +122,95 @@ func TestExpandLowRequestCountLoadBalancer_notIdle(t *testing.T) {
 		create.TestFailureAttribute(t, "lbIsIdle", "false")
 	}
 }
+
+func TestExpandIdleLoadBalancerFinding_basic(t *testing.T) {
+	idleLoadBalancer := IdleLoadBalancer{
+		AccountId:        "123456789011",
+		Region:           "us-east-1",
+		LoadBalancerName: "my-load-balancer",
+		Reason:           IdleLoadBalancerReasonNoActiveInstances,
+	}
+	arn := "arn:aws:elasticloadbalancing:us-east-1:123456789011:loadbalancer/app/my-load-balancer/50dc6c495c0c9188"
+
+	finding := expandIdleLoadBalancerFinding(idleLoadBalancer, arn)
+
+	if finding.ResourceId != "my-load-balancer" {
+		create.TestFailureAttribute(t, "ResourceId", "my-load-balancer")
+	}
+	if finding.ResourceArn != arn {
+		create.TestFailureAttribute(t, "ResourceArn", arn)
+	}
+	if finding.Reason != IdleLoadBalancerReasonNoActiveInstances {
+		create.TestFailureAttribute(t, "Reason", IdleLoadBalancerReasonNoActiveInstances)
+	}
+}
+
+func TestLoadBalancerDimension_basic(t *testing.T) {
+	dimension := loadBalancerDimension("arn:aws:elasticloadbalancing:us-east-1:123456789011:loadbalancer/app/my-load-balancer/50dc6c495c0c9188")
+
+	if dimension != "app/my-load-balancer/50dc6c495c0c9188" {
+		t.Fatalf(`LoadBalancer dimension should be app/my-load-balancer/50dc6c495c0c9188, Got %s`, dimension)
+	}
+}
+
+func TestIdleLoadBalancersRun_fixture(t *testing.T) {
+	tagFilter, err := client.ParseTagFilter([]string{"owner=platform"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := awstest.RunCases(t, func() internalAws.Check { return new(IdleLoadBalancersCheck) }, []awstest.Case{
+		{
+			Name:                    "idle load balancers",
+			Fixture:                 "testdata/IdleLoadBalancers.json",
+			ResourcesEvaluated:      4,
+			ResourcesFlagged:        3,
+			Status:                  common.StatusWarning,
+			ResourceIds:             []string{"empty", "unhealthy", "quiet"},
+			EstimatedMonthlySavings: 54.75,
+		},
+		{
+			Name:    "network and gateway load balancers",
+			Fixture: "testdata/IdleLoadBalancersNetwork.json",
+			Configure: func(conn *client.AWSClient) {
+				conn.Metrics = client.NewMetrics(stubTrafficMetrics{"AWS/NetworkELB": "NewFlowCount", "AWS/GatewayELB": "NewFlowCount"}, time.Now())
+			},
+			ResourcesEvaluated: 2,
+			ResourcesFlagged:   0,
+			Status:             common.StatusOk,
+		},
+		{
+			Name:    "tag filter",
+			Fixture: "testdata/IdleLoadBalancersTagFilter.json",
+			Configure: func(conn *client.AWSClient) {
+				conn.TagFilter = tagFilter
+			},
+			ResourcesEvaluated:      2,
+			ResourcesFlagged:        1,
+			Status:                  common.StatusWarning,
+			ResourceIds:             []string{"quiet"},
+			EstimatedMonthlySavings: 18.25,
+		},
+	})
+	if results[2].Summary().Findings[0].Tags["cost-center"] != "1234" {
+		create.TestFailureAttribute(t, "Tags", "1234")
+	}
+}
+
+// stubTrafficMetrics returns busy daily traffic for the metric of each
+// namespace, and no datapoints for any other metric.
+type stubTrafficMetrics map[string]string
+
+func (s stubTrafficMetrics) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	out := &cloudwatch.GetMetricDataOutput{}
+	for _, query := range params.MetricDataQueries {
+		result := cloudwatchTypes.MetricDataResult{Id: query.Id}
+		metric := query.MetricStat.Metric
+		if s[aws.ToString(metric.Namespace)] == aws.ToString(metric.MetricName) {
+			result.Timestamps = []time.Time{time.Now()}
+			result.Values = []float64{5000}
+		}
+		out.MetricDataResults = append(out.MetricDataResults, result)
+	}
+	return out, nil
+}
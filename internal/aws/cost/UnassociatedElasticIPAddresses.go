@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
 	"github.com/brittandeyoung/ckia/internal/client"
 	"github.com/brittandeyoung/ckia/internal/common"
 )
@@ -17,33 +18,50 @@ const (
 	UnassociatedElasticIPAddressesCheckCriteria            = "An allocated Elastic IP address (EIP) is not associated with a running Amazon EC2 instance."
 	UnassociatedElasticIPAddressesCheckRecommendedAction   = "Associate the EIP with a running active instance, or release the unassociated EIP. "
 	UnassociatedElasticIPAddressesCheckAdditionalResources = "See comparable AWS Trusted advisor check: https://docs.aws.amazon.com/awssupport/latest/user/cost-optimization-checks.html#unassociated-elastic-ip-addresses"
+
+	UnassociatedElasticIPAddressReasonNotAssociated = "not associated with a running instance"
 )
 
 type UnassociatedElasticIPAddress struct {
-	Region    string `json:"region"`
-	IPAddress string `json:"IPAddress"`
+	AccountId               string            `json:"accountId"`
+	AccountName             string            `json:"accountName,omitempty"`
+	Region                  string            `json:"region"`
+	IPAddress               string            `json:"IPAddress"`
+	AllocationId            string            `json:"allocationId"`
+	EstimatedMonthlySavings float64           `json:"estimatedMonthlySavings"`
+	Tags                    map[string]string `json:"tags,omitempty"`
 }
 
 type UnassociatedElasticIPAddressesCheck struct {
 	common.Check
+	common.CheckResult
 	UnassociatedElasticIPAddresses []UnassociatedElasticIPAddress `json:"unassociatedAddresses"`
 }
 
-func (v *UnassociatedElasticIPAddressesCheck) List() *UnassociatedElasticIPAddressesCheck {
-	v.Check = common.Check{
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(UnassociatedElasticIPAddressesCheck) })
+}
+
+func (v *UnassociatedElasticIPAddressesCheck) Metadata() common.Check {
+	return common.Check{
 		Id:                  UnassociatedElasticIPAddressesCheckId,
+		Category:            common.CategoryCostOptimization,
+		Severity:            common.SeverityLow,
 		Name:                UnassociatedElasticIPAddressesCheckName,
 		Description:         UnassociatedElasticIPAddressesCheckDescription,
 		Criteria:            UnassociatedElasticIPAddressesCheckCriteria,
 		RecommendedAction:   UnassociatedElasticIPAddressesCheckRecommendedAction,
 		AdditionalResources: UnassociatedElasticIPAddressesCheckAdditionalResources,
+		Permissions: []string{
+			"ec2:DescribeAddresses",
+			"pricing:GetProducts",
+		},
 	}
-
-	return v
 }
 
-func (v *UnassociatedElasticIPAddressesCheck) Run(ctx context.Context, conn client.AWSClient) (*UnassociatedElasticIPAddressesCheck, error) {
-	v = v.List()
+func (v *UnassociatedElasticIPAddressesCheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.Check = v.Metadata()
+	v.CheckResult = common.NewCheckResult(conn.AccountId, conn.Region, params)
 
 	in := &ec2.DescribeAddressesInput{}
 	out, err := conn.EC2.DescribeAddresses(ctx, in)
@@ -52,30 +70,60 @@ func (v *UnassociatedElasticIPAddressesCheck) Run(ctx context.Context, conn clie
 		return nil, err
 	}
 
-	if len(out.Addresses) == 0 {
-		return nil, nil
+	var addresses []types.Address
+	for _, address := range out.Addresses {
+		if conn.TagFilter.Match(client.EC2Tags(address.Tags)) {
+			addresses = append(addresses, address)
+		}
 	}
 
+	v.ResourcesEvaluated = len(addresses)
+
 	var unassociatedAddresses []UnassociatedElasticIPAddress
-	for _, address := range out.Addresses {
+	for _, address := range addresses {
 
 		unassociatedAddress := expandUnassociatedAddress(conn, address)
 
 		if unassociatedAddress.IPAddress != "" {
+			unassociatedAddress.EstimatedMonthlySavings = conn.Estimator.ElasticIPAddressMonthlyCost(ctx, conn.Region)
 			unassociatedAddresses = append(unassociatedAddresses, unassociatedAddress)
+			v.AddFinding(expandUnassociatedAddressFinding(conn, unassociatedAddress))
 
 		}
 	}
 
 	v.UnassociatedElasticIPAddresses = unassociatedAddresses
+	v.Evaluate(v.Severity)
 	return v, nil
 }
 
 func expandUnassociatedAddress(conn client.AWSClient, address types.Address) UnassociatedElasticIPAddress {
 	var unassociatedAddress UnassociatedElasticIPAddress
 	if address.AssociationId == nil {
+		unassociatedAddress.AccountId = conn.AccountId
+		unassociatedAddress.AccountName = conn.AccountName
 		unassociatedAddress.Region = conn.Region
 		unassociatedAddress.IPAddress = aws.ToString(address.PublicIp)
+		unassociatedAddress.AllocationId = aws.ToString(address.AllocationId)
+		unassociatedAddress.Tags = client.EC2Tags(address.Tags)
 	}
 	return unassociatedAddress
 }
+
+func expandUnassociatedAddressFinding(conn client.AWSClient, unassociatedAddress UnassociatedElasticIPAddress) common.Finding {
+	finding := common.Finding{
+		ResourceId:              unassociatedAddress.IPAddress,
+		AccountId:               unassociatedAddress.AccountId,
+		AccountName:             unassociatedAddress.AccountName,
+		Region:                  unassociatedAddress.Region,
+		Reason:                  UnassociatedElasticIPAddressReasonNotAssociated,
+		EstimatedMonthlySavings: unassociatedAddress.EstimatedMonthlySavings,
+		Tags:                    unassociatedAddress.Tags,
+	}
+	if unassociatedAddress.AllocationId != "" {
+		finding.ResourceId = unassociatedAddress.AllocationId
+		finding.ResourceArn = conn.Arn("ec2", unassociatedAddress.Region, "elastic-ip/"+unassociatedAddress.AllocationId)
+		finding.Metadata = map[string]string{"ipAddress": unassociatedAddress.IPAddress}
+	}
+	return finding
+}
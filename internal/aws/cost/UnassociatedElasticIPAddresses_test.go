@@ -1,14 +1,15 @@
 package cost
 
 import (
-	"context"
-	"log"
+	"reflect"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
 	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
 	"github.com/brittandeyoung/ckia/internal/create"
 )
 
@@ -20,16 +21,11 @@ func TestExpandUnassociatedAddress_basic(t *testing.T) {
 		PublicIpv4Pool:     aws.String("amazon"),
 		NetworkBorderGroup: aws.String("us-east-1"),
 	}
-	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
-	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
-	}
-	conn := client.InitiateClient(cfg)
+	conn := client.AWSClient{Region: "us-east-1"}
 
 	unassociatedAddress := expandUnassociatedAddress(conn, address)
 
-	if unassociatedAddress == (UnassociatedElasticIPAddress{}) {
+	if reflect.DeepEqual(unassociatedAddress, UnassociatedElasticIPAddress{}) {
 		create.TestFailureEmptyStruct(t)
 	}
 
@@ -51,16 +47,28 @@ func TestExpandUnassociatedAddress_none(t *testing.T) {
 		PublicIpv4Pool:     aws.String("amazon"),
 		NetworkBorderGroup: aws.String("us-east-1"),
 	}
-	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
-	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
-	}
-	conn := client.InitiateClient(cfg)
+	conn := client.AWSClient{Region: "us-east-1"}
 
 	unassociatedAddress := expandUnassociatedAddress(conn, address)
 
-	if unassociatedAddress != (UnassociatedElasticIPAddress{}) {
+	if !reflect.DeepEqual(unassociatedAddress, UnassociatedElasticIPAddress{}) {
 		create.TestFailureNonEmptyStruct(t)
 	}
 }
+
+func TestUnassociatedElasticIPAddressesRun_fixture(t *testing.T) {
+	results := awstest.RunCases(t, func() internalAws.Check { return new(UnassociatedElasticIPAddressesCheck) }, []awstest.Case{
+		{
+			Name:                    "unassociated address",
+			Fixture:                 "testdata/UnassociatedElasticIPAddresses.json",
+			ResourcesEvaluated:      2,
+			ResourcesFlagged:        1,
+			Status:                  common.StatusWarning,
+			ResourceIds:             []string{"eipalloc-0287c07cca688eb9a"},
+			EstimatedMonthlySavings: 3.65,
+		},
+	})
+	if results[0].Summary().Findings[0].Tags["Name"] != "bastion" {
+		create.TestFailureAttribute(t, "Tags", "bastion")
+	}
+}
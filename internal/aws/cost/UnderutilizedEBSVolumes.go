@@ -2,13 +2,15 @@ package cost
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	cloudWatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
 	"github.com/brittandeyoung/ckia/internal/client"
 	"github.com/brittandeyoung/ckia/internal/common"
 )
@@ -17,45 +19,80 @@ const (
 	UnderutilizedEBSVolumesCheckId                  = "ckia:aws:cost:UnderutilizedEBSVolumes"
 	UnderutilizedEBSVolumesCheckName                = "Underutilized Amazon EBS Volumes"
 	UnderutilizedEBSVolumesCheckDescription         = "Checks Amazon Elastic Block Store (Amazon EBS) volume configurations and warns when volumes appear to be underutilized. Charges begin when a volume is created. If a volume remains unattached or has very low write activity (excluding boot volumes) for a period of time, the volume is underutilized. We recommend that you remove underutilized volumes to reduce costs."
-	UnderutilizedEBSVolumesCheckCriteria            = "A volume is unattached or had less than 1 IOPS per day for the past 7 days."
+	UnderutilizedEBSVolumesCheckCriteria            = "A volume is unattached or had less than %g IOPS per day for the past %d days."
 	UnderutilizedEBSVolumesCheckRecommendedAction   = "Consider creating a snapshot and deleting the volume to reduce costs."
 	UnderutilizedEBSVolumesCheckAdditionalResources = "See comparable AWS Trusted advisor check: https://docs.aws.amazon.com/awssupport/latest/user/cost-optimization-checks.html#underutilized-amazon-ebs-volumes"
+
+	UnderutilizedEBSVolumeReasonUnattached = "unattached with no read activity"
+
+	UnderutilizedEBSVolumesParameterLookbackDays  = "lookbackDays"
+	UnderutilizedEBSVolumesParameterMinIOPSPerDay = "minIOPSPerDay"
 )
 
 type UnderutilizedEBSVolume struct {
-	Region             string `json:"region"`
-	VolumeId           string `json:"volumeId"`
-	VolumeName         string `json:"volumeName"`
-	VolumeType         string `json:"volumeType"`
-	VolumeSize         int    `json:"volumeSize"`
-	MonthlyStorageCost int    `json:"monthlyStorageCost"`
-	SnapshotId         string `json:"snapshotId"`
-	SnapshotName       string `json:"snapshotName"`
-	SnapshotAge        int    `json:"snapshotAge"`
+	AccountId          string            `json:"accountId"`
+	AccountName        string            `json:"accountName,omitempty"`
+	Region             string            `json:"region"`
+	VolumeId           string            `json:"volumeId"`
+	VolumeName         string            `json:"volumeName"`
+	VolumeType         string            `json:"volumeType"`
+	VolumeSize         int               `json:"volumeSize"`
+	MonthlyStorageCost float64           `json:"monthlyStorageCost"`
+	SnapshotId         string            `json:"snapshotId"`
+	SnapshotName       string            `json:"snapshotName"`
+	SnapshotAge        int               `json:"snapshotAge"`
+	Tags               map[string]string `json:"tags,omitempty"`
 }
 
 type UnderutilizedEBSVolumesCheck struct {
 	common.Check
+	common.CheckResult
 	UnderutilizedEBSVolumes []UnderutilizedEBSVolume `json:"underutilizedVolumes"`
 }
 
-func (v *UnderutilizedEBSVolumesCheck) List() *UnderutilizedEBSVolumesCheck {
-	v.Check = common.Check{
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(UnderutilizedEBSVolumesCheck) })
+}
+
+func (v *UnderutilizedEBSVolumesCheck) Metadata() common.Check {
+	check := common.Check{
 		Id:                  UnderutilizedEBSVolumesCheckId,
+		Category:            common.CategoryCostOptimization,
+		Severity:            common.SeverityLow,
 		Name:                UnderutilizedEBSVolumesCheckName,
 		Description:         UnderutilizedEBSVolumesCheckDescription,
 		Criteria:            UnderutilizedEBSVolumesCheckCriteria,
 		RecommendedAction:   UnderutilizedEBSVolumesCheckRecommendedAction,
 		AdditionalResources: UnderutilizedEBSVolumesCheckAdditionalResources,
+		Permissions: []string{
+			"ec2:DescribeVolumes",
+			"ec2:DescribeSnapshots",
+			"cloudwatch:GetMetricData",
+			"pricing:GetProducts",
+		},
+		Parameters: []common.Parameter{
+			{
+				Name:        UnderutilizedEBSVolumesParameterLookbackDays,
+				Description: "The number of days of read metrics to evaluate.",
+				Default:     14,
+				Integer:     true,
+				Min:         1,
+			},
+			{
+				Name:        UnderutilizedEBSVolumesParameterMinIOPSPerDay,
+				Description: "An unattached volume with no data point at or above this many IOPS is considered underutilized.",
+				Default:     1,
+			},
+		},
 	}
-
-	return v
+	return check.Describe(v.EffectiveParameters, func(check *common.Check, params common.Parameters) {
+		check.Criteria = fmt.Sprintf(UnderutilizedEBSVolumesCheckCriteria, params.Float(UnderutilizedEBSVolumesParameterMinIOPSPerDay), params.Int(UnderutilizedEBSVolumesParameterLookbackDays))
+	})
 }
 
-func (v *UnderutilizedEBSVolumesCheck) Run(ctx context.Context, conn client.AWSClient) (*UnderutilizedEBSVolumesCheck, error) {
-	v = v.List()
-
-	currentTime := time.Now()
+func (v *UnderutilizedEBSVolumesCheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.CheckResult = common.NewCheckResult(conn.AccountId, conn.Region, params)
+	v.Check = v.Metadata()
 
 	in := &ec2.DescribeVolumesInput{}
 	var volumes []types.Volume
@@ -68,39 +105,50 @@ func (v *UnderutilizedEBSVolumesCheck) Run(ctx context.Context, conn client.AWSC
 		if err != nil {
 			return nil, err
 		}
-		volumes = append(volumes, output.Volumes...)
+		for _, volume := range output.Volumes {
+			if conn.TagFilter.Match(client.EC2Tags(volume.Tags)) {
+				volumes = append(volumes, volume)
+			}
+		}
 
 	}
 
-	if len(volumes) == 0 {
-		return nil, nil
-	}
+	v.ResourcesEvaluated = len(volumes)
 
-	var underutilizedVolumes []UnderutilizedEBSVolume
+	// Only unattached volumes can be underutilized, so metrics are only
+	// fetched for those.
+	var unattachedVolumes []types.Volume
+	var queries []client.MetricQuery
 	for _, volume := range volumes {
-
-		var underutilizedVolume UnderutilizedEBSVolume
-
-		metrics, err := conn.Cloudwatch.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
-			MetricName: aws.String("VolumeReadOps"),
-			Period:     aws.Int32(3600),
-			Namespace:  aws.String("AWS/EBS"),
-			Statistics: []cloudWatchTypes.Statistic{cloudWatchTypes.StatisticSum},
+		if volume.State != types.VolumeStateAvailable {
+			continue
+		}
+		unattachedVolumes = append(unattachedVolumes, volume)
+		queries = append(queries, client.MetricQuery{
+			Namespace:  "AWS/EBS",
+			MetricName: "VolumeReadOps",
 			Dimensions: []cloudWatchTypes.Dimension{
 				{
-					Name:  aws.String("DBInstanceIdentifier"),
+					Name:  aws.String("VolumeId"),
 					Value: volume.VolumeId,
 				},
 			},
-			StartTime: aws.Time(currentTime.AddDate(0, 0, -14)),
-			EndTime:   aws.Time(currentTime),
+			Stat:         cloudWatchTypes.StatisticSum,
+			Period:       86400,
+			LookbackDays: params.Int(UnderutilizedEBSVolumesParameterLookbackDays),
 		})
+	}
+	metrics, err := conn.Metrics.Datapoints(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	var underutilizedVolumes []UnderutilizedEBSVolume
+	for i, volume := range unattachedVolumes {
+
+		var underutilizedVolume UnderutilizedEBSVolume
 
-		underutilizedVolume = expandUnderutilizedVolume(conn, volume, metrics.Datapoints)
+		underutilizedVolume = expandUnderutilizedVolume(conn, volume, metrics[i], params.Float(UnderutilizedEBSVolumesParameterMinIOPSPerDay))
 
 		if underutilizedVolume.SnapshotId != "" {
 			snapshots, err := conn.EC2.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{
@@ -112,35 +160,35 @@ func (v *UnderutilizedEBSVolumesCheck) Run(ctx context.Context, conn client.AWSC
 			}
 
 			underutilizedVolume = expandSnapshot(snapshots.Snapshots, underutilizedVolume)
-			// Still trying to figure out how to get the proper pricing via the API
-			// underutilizedVolume.MonthlyStorageCost = 0
 		}
 		if underutilizedVolume.VolumeId != "" {
+			underutilizedVolume.MonthlyStorageCost = conn.Estimator.EBSVolumeMonthlyCost(ctx, conn.Region, underutilizedVolume.VolumeType, underutilizedVolume.VolumeSize)
 			underutilizedVolumes = append(underutilizedVolumes, underutilizedVolume)
+			v.AddFinding(expandUnderutilizedVolumeFinding(conn, underutilizedVolume))
 
 		}
 	}
 
 	v.UnderutilizedEBSVolumes = underutilizedVolumes
+	v.Evaluate(v.Severity)
 	return v, nil
 }
 
-func expandUnderutilizedVolume(conn client.AWSClient, volume types.Volume, dataPoints []cloudWatchTypes.Datapoint) UnderutilizedEBSVolume {
+func expandUnderutilizedVolume(conn client.AWSClient, volume types.Volume, dataPoints []cloudWatchTypes.Datapoint, minIOPSPerDay float64) UnderutilizedEBSVolume {
 	var underutilizedVolume UnderutilizedEBSVolume
 	iopsFound := false
 	for _, dataPoint := range dataPoints {
-		if aws.ToFloat64(dataPoint.Average) != 0 {
+		if aws.ToFloat64(dataPoint.Sum) >= minIOPSPerDay {
 			iopsFound = true
 		}
 	}
 	if volume.State == types.VolumeStateAvailable && !iopsFound {
+		underutilizedVolume.AccountId = conn.AccountId
+		underutilizedVolume.AccountName = conn.AccountName
 		underutilizedVolume.Region = conn.Region
 		underutilizedVolume.VolumeId = aws.ToString(volume.VolumeId)
-		for _, tag := range volume.Tags {
-			if aws.ToString(tag.Key) == "Name" {
-				underutilizedVolume.VolumeName = aws.ToString(tag.Value)
-			}
-		}
+		underutilizedVolume.Tags = client.EC2Tags(volume.Tags)
+		underutilizedVolume.VolumeName = underutilizedVolume.Tags["Name"]
 		underutilizedVolume.VolumeType = aws.ToString((*string)(&volume.VolumeType))
 		underutilizedVolume.VolumeSize = int(aws.ToInt32(volume.Size))
 		underutilizedVolume.SnapshotId = aws.ToString(volume.SnapshotId)
@@ -148,16 +196,29 @@ func expandUnderutilizedVolume(conn client.AWSClient, volume types.Volume, dataP
 	return underutilizedVolume
 }
 
+func expandUnderutilizedVolumeFinding(conn client.AWSClient, underutilizedVolume UnderutilizedEBSVolume) common.Finding {
+	return common.Finding{
+		ResourceId:              underutilizedVolume.VolumeId,
+		ResourceArn:             conn.Arn("ec2", underutilizedVolume.Region, "volume/"+underutilizedVolume.VolumeId),
+		AccountId:               underutilizedVolume.AccountId,
+		AccountName:             underutilizedVolume.AccountName,
+		Region:                  underutilizedVolume.Region,
+		Reason:                  UnderutilizedEBSVolumeReasonUnattached,
+		EstimatedMonthlySavings: underutilizedVolume.MonthlyStorageCost,
+		Tags:                    underutilizedVolume.Tags,
+		Metadata: map[string]string{
+			"volumeType": underutilizedVolume.VolumeType,
+			"volumeSize": strconv.Itoa(underutilizedVolume.VolumeSize),
+		},
+	}
+}
+
 func expandSnapshot(snapshots []types.Snapshot, volume UnderutilizedEBSVolume) UnderutilizedEBSVolume {
 	if len(snapshots) > 0 {
 		snapshot := snapshots[0]
 		duration := time.Since(aws.ToTime(snapshot.StartTime))
 		volume.SnapshotAge = int(duration.Hours() / 24)
-		for _, tag := range snapshot.Tags {
-			if aws.ToString(tag.Key) == "Name" {
-				volume.SnapshotName = aws.ToString(tag.Value)
-			}
-		}
+		volume.SnapshotName = client.EC2Tags(snapshot.Tags)["Name"]
 	}
 	return volume
 }
@@ -1,16 +1,17 @@
 package cost
 
 import (
-	"context"
-	"log"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
 	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
 	"github.com/brittandeyoung/ckia/internal/create"
 )
 
@@ -18,37 +19,37 @@ func TestExpandUnderutilizedVolume_basic(t *testing.T) {
 	dataPoints := []types.Datapoint{
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 	}
@@ -66,15 +67,10 @@ func TestExpandUnderutilizedVolume_basic(t *testing.T) {
 			},
 		},
 	}
-	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
-	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
-	}
-	conn := client.InitiateClient(cfg)
-	underutilizedVolume := expandUnderutilizedVolume(conn, volume, dataPoints)
+	conn := client.AWSClient{Region: "us-east-1"}
+	underutilizedVolume := expandUnderutilizedVolume(conn, volume, dataPoints, 1)
 
-	if underutilizedVolume == (UnderutilizedEBSVolume{}) {
+	if reflect.DeepEqual(underutilizedVolume, UnderutilizedEBSVolume{}) {
 		create.TestFailureNonEmptyStruct(t)
 	}
 	if underutilizedVolume.Region != "us-east-1" {
@@ -101,37 +97,37 @@ func TestExpandUnderutilizedVolume_attachedVolume(t *testing.T) {
 	dataPoints := []types.Datapoint{
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 	}
@@ -149,15 +145,10 @@ func TestExpandUnderutilizedVolume_attachedVolume(t *testing.T) {
 			},
 		},
 	}
-	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
-	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
-	}
-	conn := client.InitiateClient(cfg)
-	underutilizedVolume := expandUnderutilizedVolume(conn, volume, dataPoints)
+	conn := client.AWSClient{Region: "us-east-1"}
+	underutilizedVolume := expandUnderutilizedVolume(conn, volume, dataPoints, 1)
 
-	if underutilizedVolume != (UnderutilizedEBSVolume{}) {
+	if !reflect.DeepEqual(underutilizedVolume, UnderutilizedEBSVolume{}) {
 		create.TestFailureNonEmptyStruct(t)
 	}
 }
@@ -166,37 +157,37 @@ func TestExpandUnderutilizedVolume_activeVolume(t *testing.T) {
 	dataPoints := []types.Datapoint{
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(5.0),
+			Sum:       aws.Float64(5.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(1.0),
+			Sum:       aws.Float64(1.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(6.0),
+			Sum:       aws.Float64(6.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 		{
 			Timestamp: aws.Time(time.Now()),
-			Average:   aws.Float64(0.0),
+			Sum:       aws.Float64(0.0),
 			Unit:      "Count",
 		},
 	}
@@ -214,15 +205,10 @@ func TestExpandUnderutilizedVolume_activeVolume(t *testing.T) {
 			},
 		},
 	}
-	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
-	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
-	}
-	conn := client.InitiateClient(cfg)
-	underutilizedVolume := expandUnderutilizedVolume(conn, volume, dataPoints)
+	conn := client.AWSClient{Region: "us-east-1"}
+	underutilizedVolume := expandUnderutilizedVolume(conn, volume, dataPoints, 1)
 
-	if underutilizedVolume != (UnderutilizedEBSVolume{}) {
+	if !reflect.DeepEqual(underutilizedVolume, UnderutilizedEBSVolume{}) {
 		create.TestFailureNonEmptyStruct(t)
 	}
 }
@@ -251,3 +237,50 @@ func TestExpandSnapshot_basic(t *testing.T) {
 	}
 
 }
+
+func TestExpandUnderutilizedVolumeFinding_basic(t *testing.T) {
+	conn := client.AWSClient{AccountId: "123456789011", Partition: "aws", Region: "us-east-1"}
+	underutilizedVolume := UnderutilizedEBSVolume{
+		AccountId:  "123456789011",
+		Region:     "us-east-1",
+		VolumeId:   "vol-02e71c945942481e85",
+		VolumeType: "gp2",
+		VolumeSize: 20,
+	}
+
+	finding := expandUnderutilizedVolumeFinding(conn, underutilizedVolume)
+
+	if finding.ResourceId != "vol-02e71c945942481e85" {
+		create.TestFailureAttribute(t, "ResourceId", "vol-02e71c945942481e85")
+	}
+	if finding.ResourceArn != "arn:aws:ec2:us-east-1:123456789011:volume/vol-02e71c945942481e85" {
+		create.TestFailureAttribute(t, "ResourceArn", "arn:aws:ec2:us-east-1:123456789011:volume/vol-02e71c945942481e85")
+	}
+	if finding.Reason != UnderutilizedEBSVolumeReasonUnattached {
+		create.TestFailureAttribute(t, "Reason", UnderutilizedEBSVolumeReasonUnattached)
+	}
+	if finding.Metadata["volumeSize"] != "20" {
+		create.TestFailureAttribute(t, "Metadata.volumeSize", "20")
+	}
+}
+
+func TestUnderutilizedEBSVolumesRun_fixture(t *testing.T) {
+	results := awstest.RunCases(t, func() internalAws.Check { return new(UnderutilizedEBSVolumesCheck) }, []awstest.Case{
+		{
+			Name:                    "unattached volume",
+			Fixture:                 "testdata/UnderutilizedEBSVolumes.json",
+			ResourcesEvaluated:      3,
+			ResourcesFlagged:        1,
+			Status:                  common.StatusWarning,
+			ResourceIds:             []string{"vol-02e71c945942481e8"},
+			EstimatedMonthlySavings: 2,
+		},
+	})
+	volume := results[0].(*UnderutilizedEBSVolumesCheck).UnderutilizedEBSVolumes[0]
+	if volume.SnapshotName != "old-data-snapshot" {
+		create.TestFailureAttribute(t, "SnapshotName", "old-data-snapshot")
+	}
+	if volume.SnapshotAge <= 0 {
+		t.Fatalf("SnapshotAge should be set from the snapshot start time, Got %d", volume.SnapshotAge)
+	}
+}
@@ -0,0 +1,132 @@
+package faulttolerance
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	RDSSingleAZInstancesCheckId                  = "ckia:aws:faulttolerance:RDSSingleAZInstances"
+	RDSSingleAZInstancesCheckName                = "Amazon RDS Multi-AZ"
+	RDSSingleAZInstancesCheckDescription         = "Checks for DB instances that are deployed in a single Availability Zone (AZ). Multi-AZ deployments enhance database availability by synchronously replicating to a standby instance in a different Availability Zone. During planned database maintenance or the failure of a DB instance or Availability Zone, Amazon RDS automatically fails over to the standby. This check does not evaluate DB instances that are members of an Amazon Aurora cluster."
+	RDSSingleAZInstancesCheckCriteria            = "A DB instance is deployed in a single Availability Zone."
+	RDSSingleAZInstancesCheckRecommendedAction   = "If the DB instance is used in a production environment, consider enabling a Multi-AZ deployment. Multi-AZ deployments increase the cost of the DB instance."
+	RDSSingleAZInstancesCheckAdditionalResources = "See comparable AWS Trusted advisor check: https://docs.aws.amazon.com/awssupport/latest/user/fault-tolerance-checks.html#amazon-rds-multi-az"
+
+	RDSSingleAZInstanceReasonSingleAZ = "deployed in a single Availability Zone"
+)
+
+type RDSSingleAZInstance struct {
+	AccountId        string            `json:"accountId"`
+	AccountName      string            `json:"accountName,omitempty"`
+	Region           string            `json:"region"`
+	DBInstanceName   string            `json:"dbInstanceName"`
+	AvailabilityZone string            `json:"availabilityZone"`
+	Engine           string            `json:"engine"`
+	InstanceType     string            `json:"instanceType"`
+	Tags             map[string]string `json:"tags,omitempty"`
+}
+
+type RDSSingleAZInstancesCheck struct {
+	common.Check
+	common.CheckResult
+	RDSSingleAZInstances []RDSSingleAZInstance `json:"singleAZInstances"`
+}
+
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(RDSSingleAZInstancesCheck) })
+}
+
+func (v *RDSSingleAZInstancesCheck) Metadata() common.Check {
+	return common.Check{
+		Id:                  RDSSingleAZInstancesCheckId,
+		Category:            common.CategoryFaultTolerance,
+		Severity:            common.SeverityMedium,
+		Name:                RDSSingleAZInstancesCheckName,
+		Description:         RDSSingleAZInstancesCheckDescription,
+		Criteria:            RDSSingleAZInstancesCheckCriteria,
+		RecommendedAction:   RDSSingleAZInstancesCheckRecommendedAction,
+		AdditionalResources: RDSSingleAZInstancesCheckAdditionalResources,
+		Permissions: []string{
+			"rds:DescribeDBInstances",
+		},
+	}
+}
+
+func (v *RDSSingleAZInstancesCheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.Check = v.Metadata()
+	v.CheckResult = common.NewCheckResult(conn.AccountId, conn.Region, params)
+
+	in := &rds.DescribeDBInstancesInput{}
+	var dbInstances []types.DBInstance
+
+	paginator := rds.NewDescribeDBInstancesPaginator(conn.RDS, in, func(o *rds.DescribeDBInstancesPaginatorOptions) {})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+		for _, dbInstance := range output.DBInstances {
+			// Aurora instances inherit availability from their cluster
+			if dbInstance.DBClusterIdentifier == nil && conn.TagFilter.Match(client.RDSTags(dbInstance.TagList)) {
+				dbInstances = append(dbInstances, dbInstance)
+			}
+		}
+	}
+
+	v.ResourcesEvaluated = len(dbInstances)
+
+	var singleAZInstances []RDSSingleAZInstance
+	for _, dbInstance := range dbInstances {
+		singleAZInstance := expandSingleAZInstance(conn, dbInstance)
+
+		if singleAZInstance.DBInstanceName != "" {
+			singleAZInstances = append(singleAZInstances, singleAZInstance)
+			v.AddFinding(expandSingleAZInstanceFinding(singleAZInstance, aws.ToString(dbInstance.DBInstanceArn)))
+		}
+	}
+
+	v.RDSSingleAZInstances = singleAZInstances
+	v.Evaluate(v.Severity)
+	return v, nil
+}
+
+func expandSingleAZInstance(conn client.AWSClient, dbInstance types.DBInstance) RDSSingleAZInstance {
+	var singleAZInstance RDSSingleAZInstance
+	if !dbInstance.MultiAZ {
+		singleAZInstance.AccountId = conn.AccountId
+		singleAZInstance.AccountName = conn.AccountName
+		singleAZInstance.Region = conn.Region
+		singleAZInstance.DBInstanceName = aws.ToString(dbInstance.DBInstanceIdentifier)
+		singleAZInstance.AvailabilityZone = aws.ToString(dbInstance.AvailabilityZone)
+		singleAZInstance.Engine = aws.ToString(dbInstance.Engine)
+		singleAZInstance.InstanceType = aws.ToString(dbInstance.DBInstanceClass)
+		singleAZInstance.Tags = client.RDSTags(dbInstance.TagList)
+	}
+	return singleAZInstance
+}
+
+func expandSingleAZInstanceFinding(singleAZInstance RDSSingleAZInstance, arn string) common.Finding {
+	return common.Finding{
+		ResourceId:  singleAZInstance.DBInstanceName,
+		ResourceArn: arn,
+		AccountId:   singleAZInstance.AccountId,
+		AccountName: singleAZInstance.AccountName,
+		Region:      singleAZInstance.Region,
+		Reason:      RDSSingleAZInstanceReasonSingleAZ,
+		Tags:        singleAZInstance.Tags,
+		Metadata: map[string]string{
+			"availabilityZone": singleAZInstance.AvailabilityZone,
+			"engine":           singleAZInstance.Engine,
+			"instanceType":     singleAZInstance.InstanceType,
+		},
+	}
+}
@@ -0,0 +1,71 @@
+package faulttolerance
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/create"
+)
+
+func TestExpandSingleAZInstance_basic(t *testing.T) {
+	dbInstance := types.DBInstance{
+		DBInstanceIdentifier: aws.String("my-database"),
+		DBInstanceClass:      aws.String("db.t3.micro"),
+		AvailabilityZone:     aws.String("us-east-1a"),
+		Engine:               aws.String("postgres"),
+		MultiAZ:              false,
+	}
+	conn := client.AWSClient{AccountId: "123456789011", Region: "us-east-1"}
+
+	singleAZInstance := expandSingleAZInstance(conn, dbInstance)
+
+	if reflect.DeepEqual(singleAZInstance, RDSSingleAZInstance{}) {
+		create.TestFailureEmptyStruct(t)
+	}
+	if singleAZInstance.DBInstanceName != "my-database" {
+		create.TestFailureAttribute(t, "DBInstanceName", "my-database")
+	}
+	if singleAZInstance.AvailabilityZone != "us-east-1a" {
+		create.TestFailureAttribute(t, "AvailabilityZone", "us-east-1a")
+	}
+	if singleAZInstance.Region != "us-east-1" {
+		create.TestFailureAttribute(t, "Region", "us-east-1")
+	}
+}
+
+func TestExpandSingleAZInstance_multiAZ(t *testing.T) {
+	dbInstance := types.DBInstance{
+		DBInstanceIdentifier: aws.String("my-database"),
+		DBInstanceClass:      aws.String("db.t3.micro"),
+		AvailabilityZone:     aws.String("us-east-1a"),
+		Engine:               aws.String("postgres"),
+		MultiAZ:              true,
+	}
+	conn := client.AWSClient{AccountId: "123456789011", Region: "us-east-1"}
+
+	singleAZInstance := expandSingleAZInstance(conn, dbInstance)
+
+	if !reflect.DeepEqual(singleAZInstance, RDSSingleAZInstance{}) {
+		create.TestFailureNonEmptyStruct(t)
+	}
+}
+
+func TestRDSSingleAZInstancesRun_fixture(t *testing.T) {
+	awstest.RunCases(t, func() internalAws.Check { return new(RDSSingleAZInstancesCheck) }, []awstest.Case{
+		{
+			Name:    "single az instance",
+			Fixture: "testdata/RDSSingleAZInstances.json",
+			// The aurora instance of the fixture is not evaluated
+			ResourcesEvaluated: 2,
+			ResourcesFlagged:   1,
+			Status:             common.StatusWarning,
+			ResourceIds:        []string{"orders"},
+		},
+	})
+}
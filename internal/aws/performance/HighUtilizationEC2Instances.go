@@ -0,0 +1,222 @@
+package performance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cloudWatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	HighUtilizationEC2InstancesCheckId                  = "ckia:aws:performance:HighUtilizationEC2Instances"
+	HighUtilizationEC2InstancesCheckName                = "High Utilization Amazon EC2 Instances"
+	HighUtilizationEC2InstancesCheckDescription         = "Checks the Amazon Elastic Compute Cloud (Amazon EC2) instances that were running at any time during the last %[1]d days. This check alerts you if the daily CPU utilization was more than %[2]g%% for %[3]d or more days. Consistent high utilization can indicate optimized, steady performance. However, it can also indicate that an application does not have enough resources."
+	HighUtilizationEC2InstancesCheckCriteria            = "Daily CPU utilization was more than %[2]g%% on %[3]d or more days within the last %[1]d days."
+	HighUtilizationEC2InstancesCheckRecommendedAction   = "Consider adding more instances. For information about scaling the number of instances based on demand, see What is Auto Scaling? Alternatively, consider moving the instance to a larger instance type."
+	HighUtilizationEC2InstancesCheckAdditionalResources = "See comparable AWS Trusted advisor check: https://docs.aws.amazon.com/awssupport/latest/user/performance-checks.html#high-utilization-amazon-ec2-instances"
+
+	HighUtilizationEC2InstanceReasonHighCPU = "daily CPU utilization above %.0f%% on %d or more of the last %d days"
+
+	HighUtilizationEC2InstancesParameterLookbackDays          = "lookbackDays"
+	HighUtilizationEC2InstancesParameterCPUThreshold          = "cpuThreshold"
+	HighUtilizationEC2InstancesParameterMinDaysAboveThreshold = "minDaysAboveThreshold"
+)
+
+type HighUtilizationEC2Instance struct {
+	AccountId             string            `json:"accountId"`
+	AccountName           string            `json:"accountName,omitempty"`
+	Region                string            `json:"region"`
+	InstanceId            string            `json:"instanceId"`
+	InstanceName          string            `json:"instanceName"`
+	InstanceType          string            `json:"instanceType"`
+	DaysAboveThreshold    int               `json:"daysAboveThreshold"`
+	AverageCPUUtilization float64           `json:"averageCPUUtilization"`
+	Tags                  map[string]string `json:"tags,omitempty"`
+}
+
+type HighUtilizationEC2InstancesCheck struct {
+	common.Check
+	common.CheckResult
+	HighUtilizationEC2Instances []HighUtilizationEC2Instance `json:"highUtilizationInstances"`
+}
+
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(HighUtilizationEC2InstancesCheck) })
+}
+
+func (v *HighUtilizationEC2InstancesCheck) Metadata() common.Check {
+	check := common.Check{
+		Id:                  HighUtilizationEC2InstancesCheckId,
+		Category:            common.CategoryPerformance,
+		Severity:            common.SeverityMedium,
+		Name:                HighUtilizationEC2InstancesCheckName,
+		Description:         HighUtilizationEC2InstancesCheckDescription,
+		Criteria:            HighUtilizationEC2InstancesCheckCriteria,
+		RecommendedAction:   HighUtilizationEC2InstancesCheckRecommendedAction,
+		AdditionalResources: HighUtilizationEC2InstancesCheckAdditionalResources,
+		Permissions: []string{
+			"ec2:DescribeInstances",
+			"cloudwatch:GetMetricData",
+		},
+		Parameters: []common.Parameter{
+			{
+				Name:        HighUtilizationEC2InstancesParameterLookbackDays,
+				Description: "The number of days of CPU utilization metrics to evaluate.",
+				Default:     14,
+				Integer:     true,
+				Min:         1,
+			},
+			{
+				Name:        HighUtilizationEC2InstancesParameterCPUThreshold,
+				Description: "The daily average CPU utilization percentage a day must exceed to count as high utilization.",
+				Default:     90,
+			},
+			{
+				Name:        HighUtilizationEC2InstancesParameterMinDaysAboveThreshold,
+				Description: "The number of high utilization days before an instance is flagged.",
+				Default:     4,
+				Integer:     true,
+				Min:         1,
+				AtMost:      HighUtilizationEC2InstancesParameterLookbackDays,
+			},
+		},
+	}
+	return check.Describe(v.EffectiveParameters, func(check *common.Check, params common.Parameters) {
+		lookbackDays := params.Int(HighUtilizationEC2InstancesParameterLookbackDays)
+		cpuThreshold := params.Float(HighUtilizationEC2InstancesParameterCPUThreshold)
+		minDaysAboveThreshold := params.Int(HighUtilizationEC2InstancesParameterMinDaysAboveThreshold)
+		check.Description = fmt.Sprintf(HighUtilizationEC2InstancesCheckDescription, lookbackDays, cpuThreshold, minDaysAboveThreshold)
+		check.Criteria = fmt.Sprintf(HighUtilizationEC2InstancesCheckCriteria, lookbackDays, cpuThreshold, minDaysAboveThreshold)
+	})
+}
+
+func (v *HighUtilizationEC2InstancesCheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.CheckResult = common.NewCheckResult(conn.AccountId, conn.Region, params)
+	v.Check = v.Metadata()
+
+	lookbackDays := params.Int(HighUtilizationEC2InstancesParameterLookbackDays)
+	cpuThreshold := params.Float(HighUtilizationEC2InstancesParameterCPUThreshold)
+	minDaysAboveThreshold := params.Int(HighUtilizationEC2InstancesParameterMinDaysAboveThreshold)
+
+	in := &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []string{string(types.InstanceStateNameRunning)},
+			},
+		},
+	}
+	var instances []types.Instance
+
+	paginator := ec2.NewDescribeInstancesPaginator(conn.EC2, in, func(o *ec2.DescribeInstancesPaginatorOptions) {})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range output.Reservations {
+			for _, instance := range reservation.Instances {
+				if conn.TagFilter.Match(client.EC2Tags(instance.Tags)) {
+					instances = append(instances, instance)
+				}
+			}
+		}
+	}
+
+	v.ResourcesEvaluated = len(instances)
+
+	queries := make([]client.MetricQuery, 0, len(instances))
+	for _, instance := range instances {
+		queries = append(queries, client.MetricQuery{
+			Namespace:  "AWS/EC2",
+			MetricName: "CPUUtilization",
+			Dimensions: []cloudWatchTypes.Dimension{
+				{
+					Name:  aws.String("InstanceId"),
+					Value: instance.InstanceId,
+				},
+			},
+			Stat:         cloudWatchTypes.StatisticAverage,
+			Period:       86400,
+			LookbackDays: lookbackDays,
+		})
+	}
+	metrics, err := conn.Metrics.Datapoints(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	var highUtilizationInstances []HighUtilizationEC2Instance
+	for i, instance := range instances {
+
+		highUtilizationInstance, isHighUtilization := expandHighUtilizationInstance(conn, instance, metrics[i], cpuThreshold, minDaysAboveThreshold)
+
+		if isHighUtilization {
+			highUtilizationInstances = append(highUtilizationInstances, highUtilizationInstance)
+			finding := expandHighUtilizationInstanceFinding(conn, highUtilizationInstance)
+			finding.Reason = fmt.Sprintf(HighUtilizationEC2InstanceReasonHighCPU, cpuThreshold, minDaysAboveThreshold, lookbackDays)
+			v.AddFinding(finding)
+		}
+	}
+
+	v.HighUtilizationEC2Instances = highUtilizationInstances
+	v.Evaluate(v.Severity)
+	return v, nil
+}
+
+func expandHighUtilizationInstance(conn client.AWSClient, instance types.Instance, dataPoints []cloudWatchTypes.Datapoint, cpuThreshold float64, minDaysAboveThreshold int) (HighUtilizationEC2Instance, bool) {
+	var highUtilizationInstance HighUtilizationEC2Instance
+	if len(dataPoints) == 0 {
+		return highUtilizationInstance, false
+	}
+
+	daysAboveThreshold := 0
+	var totalUtilization float64
+	for _, dataPoint := range dataPoints {
+		average := aws.ToFloat64(dataPoint.Average)
+		totalUtilization += average
+		if average > cpuThreshold {
+			daysAboveThreshold++
+		}
+	}
+
+	if daysAboveThreshold < minDaysAboveThreshold {
+		return highUtilizationInstance, false
+	}
+
+	highUtilizationInstance.AccountId = conn.AccountId
+	highUtilizationInstance.AccountName = conn.AccountName
+	highUtilizationInstance.Region = conn.Region
+	highUtilizationInstance.InstanceId = aws.ToString(instance.InstanceId)
+	highUtilizationInstance.Tags = client.EC2Tags(instance.Tags)
+	highUtilizationInstance.InstanceName = highUtilizationInstance.Tags["Name"]
+	highUtilizationInstance.InstanceType = string(instance.InstanceType)
+	highUtilizationInstance.DaysAboveThreshold = daysAboveThreshold
+	highUtilizationInstance.AverageCPUUtilization = totalUtilization / float64(len(dataPoints))
+	return highUtilizationInstance, true
+}
+
+func expandHighUtilizationInstanceFinding(conn client.AWSClient, highUtilizationInstance HighUtilizationEC2Instance) common.Finding {
+	return common.Finding{
+		ResourceId:  highUtilizationInstance.InstanceId,
+		ResourceArn: conn.Arn("ec2", highUtilizationInstance.Region, "instance/"+highUtilizationInstance.InstanceId),
+		AccountId:   highUtilizationInstance.AccountId,
+		AccountName: highUtilizationInstance.AccountName,
+		Region:      highUtilizationInstance.Region,
+		Tags:        highUtilizationInstance.Tags,
+		Metadata: map[string]string{
+			"instanceType":          highUtilizationInstance.InstanceType,
+			"daysAboveThreshold":    strconv.Itoa(highUtilizationInstance.DaysAboveThreshold),
+			"averageCPUUtilization": fmt.Sprintf("%.2f", highUtilizationInstance.AverageCPUUtilization),
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package performance
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/create"
+)
+
+func TestExpandHighUtilizationInstance_basic(t *testing.T) {
+	dataPoints := []types.Datapoint{
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -1)),
+			Average:   aws.Float64(95.0),
+		},
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -2)),
+			Average:   aws.Float64(92.0),
+		},
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -3)),
+			Average:   aws.Float64(99.0),
+		},
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -4)),
+			Average:   aws.Float64(94.0),
+		},
+	}
+	instance := ec2Types.Instance{
+		InstanceId:   aws.String("i-0a1b2c3d4e5f67890"),
+		InstanceType: ec2Types.InstanceTypeT3Micro,
+		Tags: []ec2Types.Tag{
+			{
+				Key:   aws.String("Name"),
+				Value: aws.String("MyInstanceName"),
+			},
+		},
+	}
+	conn := client.AWSClient{AccountId: "123456789011", Region: "us-east-1"}
+
+	highUtilizationInstance, isHighUtilization := expandHighUtilizationInstance(conn, instance, dataPoints, 90, 4)
+
+	if !isHighUtilization {
+		create.TestFailureAttribute(t, "isHighUtilization", "true")
+	}
+	if highUtilizationInstance.InstanceId != "i-0a1b2c3d4e5f67890" {
+		create.TestFailureAttribute(t, "InstanceId", "i-0a1b2c3d4e5f67890")
+	}
+	if highUtilizationInstance.InstanceName != "MyInstanceName" {
+		create.TestFailureAttribute(t, "InstanceName", "MyInstanceName")
+	}
+	if highUtilizationInstance.InstanceType != "t3.micro" {
+		create.TestFailureAttribute(t, "InstanceType", "t3.micro")
+	}
+	if highUtilizationInstance.DaysAboveThreshold != 4 {
+		create.TestFailureAttribute(t, "DaysAboveThreshold", "4")
+	}
+	if highUtilizationInstance.AverageCPUUtilization != 95 {
+		create.TestFailureAttribute(t, "AverageCPUUtilization", "95")
+	}
+}
+
+func TestExpandHighUtilizationInstance_notHighUtilization(t *testing.T) {
+	dataPoints := []types.Datapoint{
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -1)),
+			Average:   aws.Float64(95.0),
+		},
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -2)),
+			Average:   aws.Float64(92.0),
+		},
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -3)),
+			Average:   aws.Float64(40.0),
+		},
+		{
+			Timestamp: aws.Time(time.Now().AddDate(0, 0, -4)),
+			Average:   aws.Float64(94.0),
+		},
+	}
+	instance := ec2Types.Instance{
+		InstanceId:   aws.String("i-0a1b2c3d4e5f67890"),
+		InstanceType: ec2Types.InstanceTypeT3Micro,
+	}
+	conn := client.AWSClient{AccountId: "123456789011", Region: "us-east-1"}
+
+	highUtilizationInstance, isHighUtilization := expandHighUtilizationInstance(conn, instance, dataPoints, 90, 4)
+
+	if isHighUtilization {
+		create.TestFailureAttribute(t, "isHighUtilization", "false")
+	}
+	if !reflect.DeepEqual(highUtilizationInstance, HighUtilizationEC2Instance{}) {
+		create.TestFailureNonEmptyStruct(t)
+	}
+}
+
+func TestHighUtilizationEC2InstancesRun_fixture(t *testing.T) {
+	awstest.RunCases(t, func() internalAws.Check { return new(HighUtilizationEC2InstancesCheck) }, []awstest.Case{
+		{
+			Name:               "high utilization instance",
+			Fixture:            "testdata/HighUtilizationEC2Instances.json",
+			ResourcesEvaluated: 2,
+			ResourcesFlagged:   1,
+			Status:             common.StatusWarning,
+			ResourceIds:        []string{"i-0a1b2c3d4e5f60718"},
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package aws
+
+import (
+	"sort"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+// ScanPermissions are the IAM actions every scan calls on top of the actions
+// of its checks: the identity of the credentials is looked up to find the
+// account to scan, and the enabled regions are listed for the regions flag.
+var ScanPermissions = []string{
+	"ec2:DescribeRegions",
+	"sts:GetCallerIdentity",
+}
+
+// OrganizationPermissions are the IAM actions a scan of every account in the
+// organization calls to list the accounts and assume a role in each of them.
+var OrganizationPermissions = []string{
+	"organizations:ListAccounts",
+	"sts:AssumeRole",
+}
+
+// SecurityHubPermissions are the IAM actions a scan calls to publish its
+// findings to Security Hub.
+var SecurityHubPermissions = []string{
+	"securityhub:BatchImportFindings",
+}
+
+// ScanOptions are the optional features of a scan that call IAM actions on top
+// of ScanPermissions.
+type ScanOptions struct {
+	Organization       bool
+	PublishSecurityHub bool
+}
+
+// Permissions returns the IAM actions every scan with the options calls,
+// regardless of its checks.
+func (o ScanOptions) Permissions() []string {
+	permissions := append([]string{}, ScanPermissions...)
+	if o.Organization {
+		permissions = append(permissions, OrganizationPermissions...)
+	}
+	if o.PublishSecurityHub {
+		permissions = append(permissions, SecurityHubPermissions...)
+	}
+	return permissions
+}
+
+// IAMPolicy is an IAM policy document.
+type IAMPolicy struct {
+	Version   string         `json:"Version"`
+	Statement []IAMStatement `json:"Statement"`
+}
+
+// IAMStatement is a statement of an IAM policy document.
+type IAMStatement struct {
+	Sid      string   `json:"Sid"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+// Permissions returns the sorted, unique IAM actions needed to scan with the
+// given checks and options.
+func Permissions(checks []common.Check, opts ScanOptions) []string {
+	seen := map[string]bool{}
+	var actions []string
+	add := func(permissions []string) {
+		for _, permission := range permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				actions = append(actions, permission)
+			}
+		}
+	}
+	add(opts.Permissions())
+	for _, check := range checks {
+		add(check.Permissions)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// NewIAMPolicy returns the least privilege policy allowing a scan with the
+// given checks and options. Every action of the checks is read only and does
+// not support resource level permissions, so the actions are allowed on every
+// resource.
+func NewIAMPolicy(checks []common.Check, opts ScanOptions) IAMPolicy {
+	return IAMPolicy{
+		Version: "2012-10-17",
+		Statement: []IAMStatement{
+			{
+				Sid:      "CkiaChecks",
+				Effect:   "Allow",
+				Action:   Permissions(checks, opts),
+				Resource: "*",
+			},
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestNewIAMPolicy(t *testing.T) {
+	checks := []common.Check{
+		{Id: "ckia:aws:cost:UnderutilizedEBSVolumes", Permissions: []string{"ec2:DescribeVolumes", "cloudwatch:GetMetricData"}},
+		{Id: "ckia:aws:performance:HighUtilizationEC2Instances", Permissions: []string{"ec2:DescribeInstances", "cloudwatch:GetMetricData"}},
+	}
+
+	policy := NewIAMPolicy(checks, ScanOptions{})
+	if policy.Version != "2012-10-17" || len(policy.Statement) != 1 {
+		t.Fatalf("Unexpected policy, Got %+v", policy)
+	}
+	statement := policy.Statement[0]
+	if statement.Effect != "Allow" || statement.Resource != "*" {
+		t.Fatalf("Actions should be allowed on every resource, Got %+v", statement)
+	}
+	expected := []string{
+		"cloudwatch:GetMetricData",
+		"ec2:DescribeInstances",
+		"ec2:DescribeRegions",
+		"ec2:DescribeVolumes",
+		"sts:GetCallerIdentity",
+	}
+	if !reflect.DeepEqual(statement.Action, expected) {
+		t.Fatalf("Actions should be the sorted, unique actions of the checks and the scan, Got %v", statement.Action)
+	}
+}
+
+func TestNewIAMPolicy_noChecks(t *testing.T) {
+	if actions := NewIAMPolicy(nil, ScanOptions{}).Statement[0].Action; !reflect.DeepEqual(actions, ScanPermissions) {
+		t.Fatalf("Only the scan actions should be allowed without checks, Got %v", actions)
+	}
+}
+
+func TestNewIAMPolicy_scanOptions(t *testing.T) {
+	cases := []struct {
+		opts     ScanOptions
+		expected []string
+	}{
+		{ScanOptions{Organization: true}, []string{"ec2:DescribeRegions", "organizations:ListAccounts", "sts:AssumeRole", "sts:GetCallerIdentity"}},
+		{ScanOptions{PublishSecurityHub: true}, []string{"ec2:DescribeRegions", "securityhub:BatchImportFindings", "sts:GetCallerIdentity"}},
+	}
+
+	for _, c := range cases {
+		if actions := NewIAMPolicy(nil, c.opts).Statement[0].Action; !reflect.DeepEqual(actions, c.expected) {
+			t.Fatalf("Actions with options %+v should be %v, Got %v", c.opts, c.expected, actions)
+		}
+	}
+}
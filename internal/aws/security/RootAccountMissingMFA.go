@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
 	"github.com/brittandeyoung/ckia/internal/client"
 	"github.com/brittandeyoung/ckia/internal/common"
 )
@@ -17,6 +18,8 @@ const (
 	RootAccountMissingMFACheckCriteria            = "MFA is not enabled on the root account."
 	RootAccountMissingMFACheckRecommendedAction   = "Log in to your root account and activate an MFA device. "
 	RootAccountMissingMFACheckAdditionalResources = "Using Multi-Factor Authentication (MFA) Devices with AWS: https://docs.aws.amazon.com/IAM/latest/UserGuide/Using_ManagingMFA.html"
+
+	RootAccountMissingMFAReasonMFADisabled = "MFA is not enabled on the root account"
 )
 
 type RootAccountMissingMFA struct {
@@ -26,25 +29,36 @@ type RootAccountMissingMFA struct {
 
 type RootAccountMissingMFACheck struct {
 	common.Check
-	AccountId              string                  `json:"accountId"`
+	common.CheckResult
 	RootAccountsMissingMFA []RootAccountMissingMFA `json:"rootAccountsMissingMFA"`
 }
 
-func (v *RootAccountMissingMFACheck) List() *RootAccountMissingMFACheck {
-	v.Check = common.Check{
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(RootAccountMissingMFACheck) })
+}
+
+func (v *RootAccountMissingMFACheck) Metadata() common.Check {
+	return common.Check{
 		Id:                  RootAccountMissingMFACheckId,
+		Category:            common.CategorySecurity,
+		Severity:            common.SeverityCritical,
 		Name:                RootAccountMissingMFACheckName,
 		Description:         RootAccountMissingMFACheckDescription,
 		Criteria:            RootAccountMissingMFACheckCriteria,
 		RecommendedAction:   RootAccountMissingMFACheckRecommendedAction,
 		AdditionalResources: RootAccountMissingMFACheckAdditionalResources,
+		Global:              true,
+		Untagged:            true,
+		Permissions: []string{
+			"iam:GetAccountSummary",
+			"sts:GetCallerIdentity",
+		},
 	}
-
-	return v
 }
 
-func (v *RootAccountMissingMFACheck) Run(ctx context.Context, conn client.AWSClient) (*RootAccountMissingMFACheck, error) {
-	v = v.List()
+func (v *RootAccountMissingMFACheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.Check = v.Metadata()
+	v.CheckResult = common.NewCheckResult(conn.AccountId, "", params)
 
 	accountSummary, err := conn.IAM.GetAccountSummary(ctx, &iam.GetAccountSummaryInput{})
 
@@ -58,21 +72,34 @@ func (v *RootAccountMissingMFACheck) Run(ctx context.Context, conn client.AWSCli
 		return nil, err
 	}
 
-	account := expandRootAccountMissingMFA(accountSummary.SummaryMap, aws.ToString(identity.Account))
+	account := expandRootAccountMissingMFA(accountSummary.SummaryMap, aws.ToString(identity.Account), conn.AccountName)
+	v.ResourcesEvaluated = 1
 
 	if account != (RootAccountMissingMFA{}) {
 		v.RootAccountsMissingMFA = []RootAccountMissingMFA{account}
+		v.AddFinding(expandRootAccountMissingMFAFinding(conn, account))
 	}
 
+	v.Evaluate(v.Severity)
 	return v, nil
 }
 
-func expandRootAccountMissingMFA(summaryMap map[string]int32, accountNumber string) RootAccountMissingMFA {
+func expandRootAccountMissingMFA(summaryMap map[string]int32, accountNumber string, accountName string) RootAccountMissingMFA {
 	var rootAccountMissingMFA RootAccountMissingMFA
 	if summaryMap["AccountMFAEnabled"] != 1 {
 		rootAccountMissingMFA.AccountId = accountNumber
-		// Can only get account name from the organization account
-		// check.AccountName
+		// The account name is only known when scanning through the organization
+		rootAccountMissingMFA.AccountName = accountName
 	}
 	return rootAccountMissingMFA
 }
+
+func expandRootAccountMissingMFAFinding(conn client.AWSClient, account RootAccountMissingMFA) common.Finding {
+	return common.Finding{
+		ResourceId:  account.AccountId,
+		ResourceArn: conn.Arn("iam", "", "root"),
+		AccountId:   account.AccountId,
+		AccountName: account.AccountName,
+		Reason:      RootAccountMissingMFAReasonMFADisabled,
+	}
+}
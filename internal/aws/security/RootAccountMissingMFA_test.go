@@ -3,6 +3,10 @@ package security
 import (
 	"testing"
 
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
 	"github.com/brittandeyoung/ckia/internal/create"
 )
 
@@ -12,7 +16,7 @@ func TestExpandRootAccountMissingMFA_basic(t *testing.T) {
 	summaryMap["AccountMFAEnabled"] = 0
 	accountNumber := "123456789011"
 
-	account := expandRootAccountMissingMFA(summaryMap, accountNumber)
+	account := expandRootAccountMissingMFA(summaryMap, accountNumber, "")
 
 	if account == (RootAccountMissingMFA{}) {
 		create.TestFailureEmptyStruct(t)
@@ -23,15 +27,55 @@ func TestExpandRootAccountMissingMFA_basic(t *testing.T) {
 	}
 }
 
+func TestExpandRootAccountMissingMFA_accountName(t *testing.T) {
+
+	summaryMap := make(map[string]int32)
+	summaryMap["AccountMFAEnabled"] = 0
+	accountNumber := "123456789011"
+
+	account := expandRootAccountMissingMFA(summaryMap, accountNumber, "production")
+
+	if account.AccountName != "production" {
+		create.TestFailureAttribute(t, "AccountName", "production")
+	}
+}
+
 func TestExpandRootAccountMissingMFA_enabled(t *testing.T) {
 
 	summaryMap := make(map[string]int32)
 	summaryMap["AccountMFAEnabled"] = 1
 	accountNumber := "123456789011"
 
-	account := expandRootAccountMissingMFA(summaryMap, accountNumber)
+	account := expandRootAccountMissingMFA(summaryMap, accountNumber, "production")
 
 	if account != (RootAccountMissingMFA{}) {
 		create.TestFailureNonEmptyStruct(t)
 	}
 }
+
+func TestExpandRootAccountMissingMFAFinding_basic(t *testing.T) {
+	conn := client.AWSClient{AccountId: "123456789011", Partition: "aws"}
+	account := RootAccountMissingMFA{AccountId: "123456789011"}
+
+	finding := expandRootAccountMissingMFAFinding(conn, account)
+
+	if finding.ResourceArn != "arn:aws:iam::123456789011:root" {
+		create.TestFailureAttribute(t, "ResourceArn", "arn:aws:iam::123456789011:root")
+	}
+	if finding.Region != "" {
+		create.TestFailureAttribute(t, "Region", "")
+	}
+}
+
+func TestRootAccountMissingMFARun_fixture(t *testing.T) {
+	awstest.RunCases(t, func() internalAws.Check { return new(RootAccountMissingMFACheck) }, []awstest.Case{
+		{
+			Name:               "root account without mfa",
+			Fixture:            "testdata/RootAccountMissingMFA.json",
+			ResourcesEvaluated: 1,
+			ResourcesFlagged:   1,
+			Status:             common.StatusError,
+			ResourceIds:        []string{"123456789011"},
+		},
+	})
+}
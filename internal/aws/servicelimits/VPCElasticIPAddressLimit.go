@@ -0,0 +1,155 @@
+package servicelimits
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	VPCElasticIPAddressLimitCheckId                  = "ckia:aws:servicelimits:VPCElasticIPAddressLimit"
+	VPCElasticIPAddressLimitCheckName                = "VPC Elastic IP Address"
+	VPCElasticIPAddressLimitCheckDescription         = "Checks for usage that is more than %g%% of the VPC Elastic IP Address limit. Values are based on a snapshot, so your current usage might differ."
+	VPCElasticIPAddressLimitCheckCriteria            = "Usage is more than %g%% of the VPC Elastic IP address limit for the region."
+	VPCElasticIPAddressLimitCheckRecommendedAction   = "If you anticipate exceeding a service limit, request an increase directly from the Service Quotas console. Alternatively, release Elastic IP addresses that are no longer in use."
+	VPCElasticIPAddressLimitCheckAdditionalResources = "See comparable AWS Trusted advisor check: https://docs.aws.amazon.com/awssupport/latest/user/service-limits.html"
+
+	VPCElasticIPAddressLimitParameterUsageThresholdPercent = "usageThresholdPercent"
+
+	vpcMaxElasticIPsAttribute = "vpc-max-elastic-ips"
+)
+
+type VPCElasticIPAddressLimit struct {
+	AccountId    string `json:"accountId"`
+	AccountName  string `json:"accountName,omitempty"`
+	Region       string `json:"region"`
+	Limit        int    `json:"limit"`
+	CurrentUsage int    `json:"currentUsage"`
+	PercentUsed  int    `json:"percentUsed"`
+}
+
+type VPCElasticIPAddressLimitCheck struct {
+	common.Check
+	common.CheckResult
+	VPCElasticIPAddressLimits []VPCElasticIPAddressLimit `json:"elasticIPAddressLimits"`
+}
+
+func init() {
+	internalAws.RegisterCheck(func() internalAws.Check { return new(VPCElasticIPAddressLimitCheck) })
+}
+
+func (v *VPCElasticIPAddressLimitCheck) Metadata() common.Check {
+	check := common.Check{
+		Id:                  VPCElasticIPAddressLimitCheckId,
+		Category:            common.CategoryServiceLimits,
+		Severity:            common.SeverityMedium,
+		Name:                VPCElasticIPAddressLimitCheckName,
+		Description:         VPCElasticIPAddressLimitCheckDescription,
+		Criteria:            VPCElasticIPAddressLimitCheckCriteria,
+		RecommendedAction:   VPCElasticIPAddressLimitCheckRecommendedAction,
+		AdditionalResources: VPCElasticIPAddressLimitCheckAdditionalResources,
+		Untagged:            true,
+		Permissions: []string{
+			"ec2:DescribeAccountAttributes",
+			"ec2:DescribeAddresses",
+		},
+		Parameters: []common.Parameter{
+			{
+				Name:        VPCElasticIPAddressLimitParameterUsageThresholdPercent,
+				Description: "The percentage of the limit usage must exceed before it is flagged.",
+				Default:     80,
+			},
+		},
+	}
+	return check.Describe(v.EffectiveParameters, func(check *common.Check, params common.Parameters) {
+		usageThresholdPercent := params.Float(VPCElasticIPAddressLimitParameterUsageThresholdPercent)
+		check.Description = fmt.Sprintf(VPCElasticIPAddressLimitCheckDescription, usageThresholdPercent)
+		check.Criteria = fmt.Sprintf(VPCElasticIPAddressLimitCheckCriteria, usageThresholdPercent)
+	})
+}
+
+func (v *VPCElasticIPAddressLimitCheck) Run(ctx context.Context, conn client.AWSClient, params common.Parameters) (common.Result, error) {
+	v.CheckResult = common.NewCheckResult(conn.AccountId, conn.Region, params)
+	v.Check = v.Metadata()
+
+	attributes, err := conn.EC2.DescribeAccountAttributes(ctx, &ec2.DescribeAccountAttributesInput{
+		AttributeNames: []types.AccountAttributeName{vpcMaxElasticIPsAttribute},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	addresses, err := conn.EC2.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("domain"),
+				Values: []string{string(types.DomainTypeVpc)},
+			},
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	v.ResourcesEvaluated = 1
+
+	limit, limitReached := expandElasticIPAddressLimit(conn, attributes.AccountAttributes, len(addresses.Addresses), params.Float(VPCElasticIPAddressLimitParameterUsageThresholdPercent))
+
+	if limitReached {
+		v.VPCElasticIPAddressLimits = []VPCElasticIPAddressLimit{limit}
+		v.AddFinding(expandElasticIPAddressLimitFinding(limit))
+	}
+
+	v.Evaluate(v.Severity)
+	return v, nil
+}
+
+func expandElasticIPAddressLimit(conn client.AWSClient, attributes []types.AccountAttribute, usage int, usageThresholdPercent float64) (VPCElasticIPAddressLimit, bool) {
+	var limit VPCElasticIPAddressLimit
+	maxAddresses := 0
+	for _, attribute := range attributes {
+		if aws.ToString(attribute.AttributeName) != vpcMaxElasticIPsAttribute || len(attribute.AttributeValues) == 0 {
+			continue
+		}
+		value, err := strconv.Atoi(aws.ToString(attribute.AttributeValues[0].AttributeValue))
+		if err == nil {
+			maxAddresses = value
+		}
+	}
+
+	if maxAddresses == 0 || float64(usage*100) <= float64(maxAddresses)*usageThresholdPercent {
+		return limit, false
+	}
+
+	limit.AccountId = conn.AccountId
+	limit.AccountName = conn.AccountName
+	limit.Region = conn.Region
+	limit.Limit = maxAddresses
+	limit.CurrentUsage = usage
+	limit.PercentUsed = usage * 100 / maxAddresses
+	return limit, true
+}
+
+func expandElasticIPAddressLimitFinding(limit VPCElasticIPAddressLimit) common.Finding {
+	return common.Finding{
+		ResourceId:  vpcMaxElasticIPsAttribute,
+		AccountId:   limit.AccountId,
+		AccountName: limit.AccountName,
+		Region:      limit.Region,
+		Reason:      fmt.Sprintf("%d of %d VPC Elastic IP addresses in use", limit.CurrentUsage, limit.Limit),
+		Metadata: map[string]string{
+			"limit":        strconv.Itoa(limit.Limit),
+			"currentUsage": strconv.Itoa(limit.CurrentUsage),
+			"percentUsed":  strconv.Itoa(limit.PercentUsed),
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package servicelimits
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	internalAws "github.com/brittandeyoung/ckia/internal/aws"
+	"github.com/brittandeyoung/ckia/internal/aws/awstest"
+	"github.com/brittandeyoung/ckia/internal/client"
+	"github.com/brittandeyoung/ckia/internal/common"
+	"github.com/brittandeyoung/ckia/internal/create"
+)
+
+func TestExpandElasticIPAddressLimit_basic(t *testing.T) {
+	attributes := []types.AccountAttribute{
+		{
+			AttributeName: aws.String("vpc-max-elastic-ips"),
+			AttributeValues: []types.AccountAttributeValue{
+				{
+					AttributeValue: aws.String("5"),
+				},
+			},
+		},
+	}
+	conn := client.AWSClient{AccountId: "123456789011", Region: "us-east-1"}
+
+	limit, limitReached := expandElasticIPAddressLimit(conn, attributes, 5, 80)
+
+	if !limitReached {
+		create.TestFailureAttribute(t, "limitReached", "true")
+	}
+	if limit.Limit != 5 {
+		create.TestFailureAttribute(t, "Limit", "5")
+	}
+	if limit.CurrentUsage != 5 {
+		create.TestFailureAttribute(t, "CurrentUsage", "5")
+	}
+	if limit.PercentUsed != 100 {
+		create.TestFailureAttribute(t, "PercentUsed", "100")
+	}
+	if limit.Region != "us-east-1" {
+		create.TestFailureAttribute(t, "Region", "us-east-1")
+	}
+}
+
+func TestExpandElasticIPAddressLimit_belowThreshold(t *testing.T) {
+	attributes := []types.AccountAttribute{
+		{
+			AttributeName: aws.String("vpc-max-elastic-ips"),
+			AttributeValues: []types.AccountAttributeValue{
+				{
+					AttributeValue: aws.String("5"),
+				},
+			},
+		},
+	}
+	conn := client.AWSClient{AccountId: "123456789011", Region: "us-east-1"}
+
+	limit, limitReached := expandElasticIPAddressLimit(conn, attributes, 4, 80)
+
+	if limitReached {
+		create.TestFailureAttribute(t, "limitReached", "false")
+	}
+	if limit != (VPCElasticIPAddressLimit{}) {
+		create.TestFailureNonEmptyStruct(t)
+	}
+}
+
+func TestVPCElasticIPAddressLimitRun_fixture(t *testing.T) {
+	awstest.RunCases(t, func() internalAws.Check { return new(VPCElasticIPAddressLimitCheck) }, []awstest.Case{
+		{
+			Name:               "limit usage above threshold",
+			Fixture:            "testdata/VPCElasticIPAddressLimit.json",
+			ResourcesEvaluated: 1,
+			ResourcesFlagged:   1,
+			Status:             common.StatusWarning,
+			ResourceIds:        []string{"vpc-max-elastic-ips"},
+		},
+	})
+}
@@ -1,38 +1,113 @@
 package client
 
 import (
+	"context"
+	"sort"
+	"time"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	internalPricing "github.com/brittandeyoung/ckia/internal/pricing"
 )
 
+const defaultPartition = "aws"
+
 type AWSClient struct {
-	Cloudwatch *cloudwatch.Client
-	EC2        *ec2.Client
-	ELBv2      *elasticloadbalancingv2.Client
-	IAM        *iam.Client
-	Pricing    *pricing.Client
-	RDS        *rds.Client
-	Region     string
-	STS        *sts.Client
+	AccountId     string
+	AccountName   string
+	Partition     string
+	Cloudwatch    CloudwatchAPI
+	EC2           EC2API
+	Estimator     *internalPricing.Estimator
+	ELBv2         ELBv2API
+	IAM           IAMAPI
+	Metrics       *Metrics
+	Organizations OrganizationsAPI
+	Pricing       PricingAPI
+	RDS           RDSAPI
+	Region        string
+	STS           STSAPI
+	// TagFilter selects the resources evaluated by checks.
+	TagFilter TagFilter
 }
 
+// InitiateClient returns an AWSClient for the account and region of cfg. API
+// calls of every service client are retried in the adaptive retry mode and
+// share the rate limit of their service set with SetRateLimit.
 func InitiateClient(cfg aws.Config) AWSClient {
+	cfg = configureRetries(cfg, rateLimits)
 	client := AWSClient{
-		Cloudwatch: cloudwatch.NewFromConfig(cfg),
-		EC2:        ec2.NewFromConfig(cfg),
-		ELBv2:      elasticloadbalancingv2.NewFromConfig(cfg),
-		IAM:        iam.NewFromConfig(cfg),
-		Pricing:    pricing.NewFromConfig(cfg),
-		RDS:        rds.NewFromConfig(cfg),
-		Region:     cfg.Region,
-		STS:        sts.NewFromConfig(cfg),
+		Cloudwatch:    cloudwatch.NewFromConfig(cfg),
+		EC2:           ec2.NewFromConfig(cfg),
+		ELBv2:         elasticloadbalancingv2.NewFromConfig(cfg),
+		IAM:           iam.NewFromConfig(cfg),
+		Organizations: organizations.NewFromConfig(cfg),
+		Pricing: pricing.NewFromConfig(cfg, func(o *pricing.Options) {
+			o.Region = internalPricing.ApiRegion
+		}),
+		RDS:    rds.NewFromConfig(cfg),
+		Region: cfg.Region,
+		STS:    sts.NewFromConfig(cfg),
 	}
+	client.Metrics = NewMetrics(client.Cloudwatch, time.Now())
 
 	return client
 }
+
+// InitiateClients returns one AWSClient per region for the given account. Every
+// client shares the credentials and settings of cfg and only differs by region.
+func InitiateClients(cfg aws.Config, account Account, regions []string) []AWSClient {
+	clients := make([]AWSClient, 0, len(regions))
+	for _, region := range regions {
+		regionalCfg := cfg.Copy()
+		regionalCfg.Region = region
+		client := InitiateClient(regionalCfg)
+		client.AccountId = account.Id
+		client.AccountName = account.Name
+		client.Partition = account.Partition
+		clients = append(clients, client)
+	}
+
+	return clients
+}
+
+// Arn returns the arn of a resource owned by the account of the client.
+func (c AWSClient) Arn(service string, region string, resource string) string {
+	partition := c.Partition
+	if partition == "" {
+		partition = defaultPartition
+	}
+
+	return arn.ARN{
+		Partition: partition,
+		Service:   service,
+		Region:    region,
+		AccountID: c.AccountId,
+		Resource:  resource,
+	}.String()
+}
+
+// EnabledRegions returns the names of all regions enabled for the account
+// the client is authenticated against.
+func EnabledRegions(ctx context.Context, conn AWSClient) ([]string, error) {
+	out, err := conn.EC2.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var regions []string
+	for _, region := range out.Regions {
+		regions = append(regions, aws.ToString(region.RegionName))
+	}
+	sort.Strings(regions)
+
+	return regions, nil
+}
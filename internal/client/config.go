@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// ConfigOptions select the credentials, region and endpoint of the aws config.
+// Empty options fall back to the default aws config chain.
+type ConfigOptions struct {
+	// Profile is the shared config profile to load.
+	Profile string
+	// Region is the default region of the config.
+	Region string
+	// RoleArn is a role assumed with the loaded credentials.
+	RoleArn string
+	// ExternalId is passed when assuming RoleArn.
+	ExternalId string
+	// MFASerial is the serial number of the MFA device used to assume RoleArn.
+	// The MFA token is read from stdin.
+	MFASerial string
+	// EndpointURL replaces the endpoint of every service, e.g. to run against
+	// LocalStack.
+	EndpointURL string
+}
+
+// LoadConfig loads the default aws config with the given options.
+func LoadConfig(ctx context.Context, opts ConfigOptions) (aws.Config, error) {
+	if opts.RoleArn == "" && (opts.ExternalId != "" || opts.MFASerial != "") {
+		return aws.Config{}, errors.New("an external id or mfa serial can only be used with a role arn")
+	}
+
+	var optFns []func(*config.LoadOptions) error
+	if opts.Profile != "" {
+		optFns = append(optFns, config.WithSharedConfigProfile(opts.Profile))
+	}
+	if opts.Region != "" {
+		optFns = append(optFns, config.WithRegion(opts.Region))
+	}
+	if opts.EndpointURL != "" {
+		optFns = append(optFns, config.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+			return aws.Endpoint{
+				URL:               opts.EndpointURL,
+				HostnameImmutable: true,
+				SigningRegion:     region,
+			}, nil
+		})))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return cfg, err
+	}
+	// A profile missing from the shared config files is otherwise ignored.
+	if opts.Profile != "" && Profile(cfg) != opts.Profile {
+		return cfg, fmt.Errorf("profile (%s) not found in the shared config files", opts.Profile)
+	}
+
+	if opts.RoleArn != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), opts.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = roleSessionName
+			if opts.ExternalId != "" {
+				o.ExternalID = aws.String(opts.ExternalId)
+			}
+			if opts.MFASerial != "" {
+				o.SerialNumber = aws.String(opts.MFASerial)
+				o.TokenProvider = stscreds.StdinTokenProvider
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+	return cfg, nil
+}
+
+// Profile returns the shared config profile cfg was loaded from, or an empty
+// string when no profile was found.
+func Profile(cfg aws.Config) string {
+	for _, source := range cfg.ConfigSources {
+		if shared, ok := source.(config.SharedConfig); ok {
+			return shared.Profile
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+)
+
+// setupSharedConfig points the aws config chain at a shared config file with
+// a ckia profile, ignoring the environment the tests run in.
+func setupSharedConfig(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(configFile, []byte("[profile ckia]\nregion = eu-west-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsFile, []byte("[ckia]\naws_access_key_id = AKID\naws_secret_access_key = SECRET\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_ROLE_ARN"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoadConfig_profile(t *testing.T) {
+	setupSharedConfig(t)
+	ctx := context.Background()
+
+	cfg, err := LoadConfig(ctx, ConfigOptions{Profile: "ckia"})
+	if err != nil {
+		t.Fatalf("Unexpected error loading config: %s", err)
+	}
+	if cfg.Region != "eu-west-1" {
+		t.Fatalf("Region of the profile should be used, Got %s", cfg.Region)
+	}
+
+	cfg, err = LoadConfig(ctx, ConfigOptions{Profile: "ckia", Region: "us-west-2"})
+	if err != nil {
+		t.Fatalf("Unexpected error loading config: %s", err)
+	}
+	if cfg.Region != "us-west-2" {
+		t.Fatalf("Region option should override the profile, Got %s", cfg.Region)
+	}
+
+	if _, err := LoadConfig(ctx, ConfigOptions{Profile: "missing"}); err == nil {
+		t.Fatal("Expected an error for a profile that does not exist.")
+	}
+}
+
+func TestLoadConfig_endpointURL(t *testing.T) {
+	setupSharedConfig(t)
+
+	cfg, err := LoadConfig(context.Background(), ConfigOptions{Profile: "ckia", EndpointURL: "http://localhost:4566"})
+	if err != nil {
+		t.Fatalf("Unexpected error loading config: %s", err)
+	}
+	endpoint, err := cfg.EndpointResolverWithOptions.ResolveEndpoint("EC2", "eu-west-1")
+	if err != nil {
+		t.Fatalf("Unexpected error resolving endpoint: %s", err)
+	}
+	if endpoint.URL != "http://localhost:4566" || endpoint.SigningRegion != "eu-west-1" {
+		t.Fatalf("Every service should use the endpoint url, Got %+v", endpoint)
+	}
+}
+
+func TestLoadConfig_roleArn(t *testing.T) {
+	setupSharedConfig(t)
+	ctx := context.Background()
+
+	cfg, err := LoadConfig(ctx, ConfigOptions{Profile: "ckia", RoleArn: "arn:aws:iam::123456789011:role/ckia-readonly", ExternalId: "ckia", MFASerial: "arn:aws:iam::123456789011:mfa/ckia"})
+	if err != nil {
+		t.Fatalf("Unexpected error loading config: %s", err)
+	}
+	if !aws.IsCredentialsProvider(cfg.Credentials, (*stscreds.AssumeRoleProvider)(nil)) {
+		t.Fatalf("Credentials should assume the role, Got %T", cfg.Credentials)
+	}
+
+	if _, err := LoadConfig(ctx, ConfigOptions{Profile: "ckia", MFASerial: "arn:aws:iam::123456789011:mfa/ckia"}); err == nil {
+		t.Fatal("Expected an error for an mfa serial without a role arn.")
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Fixture is a recording of AWS API responses. A recording fixture captures
+// the response of every call made by the clients it is added to, and a loaded
+// fixture replays them in the same order without calling AWS, so checks can
+// be run offline, e.g. in tests.
+type Fixture struct {
+	// Region is the region the responses were recorded in.
+	Region       string        `json:"region"`
+	Interactions []Interaction `json:"interactions"`
+
+	recording bool
+	mu        sync.Mutex
+	// replayed counts the replayed calls of each operation.
+	replayed map[string]int
+}
+
+// Interaction is the recorded response of a single API call.
+type Interaction struct {
+	Service    string      `json:"service"`
+	Operation  string      `json:"operation"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// NewFixtureRecorder returns a fixture recording the responses of calls made in
+// region.
+func NewFixtureRecorder(region string) *Fixture {
+	return &Fixture{Region: region, recording: true}
+}
+
+// LoadFixture reads the fixture at path to replay its responses.
+func LoadFixture(path string) (*Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := &Fixture{}
+	if err := json.Unmarshal(content, fixture); err != nil {
+		return nil, fmt.Errorf("unable to parse fixture (%s): %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Save writes the recorded responses to path.
+func (f *Fixture) Save(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	content, err := json.MarshalIndent(f, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// APIOption returns the middleware recording or replaying responses, to be
+// added to the APIOptions of an aws config. The middleware runs right before
+// the request is sent, so replayed responses are deserialized, retried and
+// counted like responses from AWS.
+func (f *Fixture) APIOption() func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("ckiaFixture", func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+			service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+			if !f.recording {
+				return f.replay(service, operation)
+			}
+
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			if err != nil {
+				return out, metadata, err
+			}
+			if recordErr := f.record(service, operation, out); recordErr != nil {
+				return out, metadata, recordErr
+			}
+			return out, metadata, nil
+		}), middleware.After)
+	}
+}
+
+// record captures the raw response of a call, leaving its body readable for
+// the deserializers.
+func (f *Fixture) record(service string, operation string, out middleware.DeserializeOutput) error {
+	response, ok := out.RawResponse.(*smithyhttp.Response)
+	if !ok {
+		return nil
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Interactions = append(f.Interactions, Interaction{
+		Service:    service,
+		Operation:  operation,
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+		Body:       string(body),
+	})
+	return nil
+}
+
+// replay returns the next recorded response of the operation.
+func (f *Fixture) replay(service string, operation string) (middleware.DeserializeOutput, middleware.Metadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.replayed == nil {
+		f.replayed = map[string]int{}
+	}
+	key := service + " " + operation
+	call := f.replayed[key]
+	f.replayed[key]++
+
+	seen := 0
+	for _, interaction := range f.Interactions {
+		if interaction.Service != service || interaction.Operation != operation {
+			continue
+		}
+		if seen < call {
+			seen++
+			continue
+		}
+		return middleware.DeserializeOutput{
+			RawResponse: &smithyhttp.Response{Response: &http.Response{
+				StatusCode:    interaction.StatusCode,
+				Header:        interaction.Header.Clone(),
+				Body:          io.NopCloser(strings.NewReader(interaction.Body)),
+				ContentLength: int64(len(interaction.Body)),
+			}},
+		}, middleware.Metadata{}, nil
+	}
+	return middleware.DeserializeOutput{}, middleware.Metadata{}, fmt.Errorf("no recorded response for call %d of %s", call+1, key)
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestFixture_recordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	recorder := NewFixtureRecorder("us-east-1")
+	cfg := testConfig(&stubHTTPClient{})
+	cfg.APIOptions = append(cfg.APIOptions, recorder.APIOption())
+	if _, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+		t.Fatalf("Unexpected error recording the API: %s", err)
+	}
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Unexpected error saving fixture: %s", err)
+	}
+
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading fixture: %s", err)
+	}
+	if len(fixture.Interactions) != 1 || fixture.Interactions[0].Service != "STS" || fixture.Interactions[0].Operation != "GetCallerIdentity" {
+		t.Fatalf("Expected a single STS GetCallerIdentity interaction, Got %+v", fixture.Interactions)
+	}
+
+	// Replayed calls must not reach the http client.
+	httpClient := &stubHTTPClient{}
+	cfg = testConfig(httpClient)
+	cfg.APIOptions = append(cfg.APIOptions, fixture.APIOption())
+	conn := sts.NewFromConfig(cfg)
+	out, err := conn.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		t.Fatalf("Unexpected error replaying the API: %s", err)
+	}
+	if aws.ToString(out.Account) != "123456789011" {
+		t.Fatalf("Expected the recorded account, Got %s", aws.ToString(out.Account))
+	}
+	if httpClient.requests != 0 {
+		t.Fatalf("Expected no requests, Got %d", httpClient.requests)
+	}
+
+	_, err = conn.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err == nil || !strings.Contains(err.Error(), "no recorded response for call 2 of STS GetCallerIdentity") {
+		t.Fatalf("Expected an error for a call that was not recorded, Got %v", err)
+	}
+}
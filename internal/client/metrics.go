@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// MaxMetricDataQueries is the most metric queries GetMetricData accepts in a
+// single call.
+const MaxMetricDataQueries = 500
+
+// GetMetricDataAPI is the CloudWatch API used to fetch metrics.
+type GetMetricDataAPI interface {
+	GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// MetricQuery is a statistic of a metric over the last LookbackDays days.
+type MetricQuery struct {
+	Namespace    string
+	MetricName   string
+	Dimensions   []types.Dimension
+	Stat         types.Statistic
+	Period       int32
+	LookbackDays int
+}
+
+// key identifies the query in the cache of a Metrics.
+func (q MetricQuery) key() string {
+	dimensions := make([]string, 0, len(q.Dimensions))
+	for _, dimension := range q.Dimensions {
+		dimensions = append(dimensions, aws.ToString(dimension.Name)+"="+aws.ToString(dimension.Value))
+	}
+	sort.Strings(dimensions)
+	return fmt.Sprintf("%s|%s|%s|%s|%d|%d", q.Namespace, q.MetricName, strings.Join(dimensions, ","), q.Stat, q.Period, q.LookbackDays)
+}
+
+// Metrics fetches CloudWatch metrics for checks with GetMetricData, batching
+// up to MaxMetricDataQueries queries per call. Every query ends at the time the
+// Metrics was created, so checks asking for the same metric share a single
+// fetch for the run.
+type Metrics struct {
+	api GetMetricDataAPI
+	now time.Time
+
+	mu    sync.Mutex
+	cache map[string][]types.Datapoint
+}
+
+// NewMetrics returns a Metrics fetching metrics through api for the lookback
+// windows ending at now.
+func NewMetrics(api GetMetricDataAPI, now time.Time) *Metrics {
+	return &Metrics{
+		api:   api,
+		now:   now,
+		cache: map[string][]types.Datapoint{},
+	}
+}
+
+// Datapoints returns the datapoints of every query, in the order of queries.
+// Only the field of each datapoint for the statistic of its query is set, so
+// datapoints can be evaluated like those returned by GetMetricStatistics.
+func (m *Metrics) Datapoints(ctx context.Context, queries []MetricQuery) ([][]types.Datapoint, error) {
+	// Queries that are not cached are fetched per lookback window, since every
+	// GetMetricData call covers a single time range.
+	pending := map[int][]MetricQuery{}
+	seen := map[string]bool{}
+	m.mu.Lock()
+	for _, query := range queries {
+		key := query.key()
+		if _, ok := m.cache[key]; ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		pending[query.LookbackDays] = append(pending[query.LookbackDays], query)
+	}
+	m.mu.Unlock()
+
+	for lookbackDays, window := range pending {
+		for start := 0; start < len(window); start += MaxMetricDataQueries {
+			end := start + MaxMetricDataQueries
+			if end > len(window) {
+				end = len(window)
+			}
+			fetched, err := m.fetch(ctx, window[start:end], lookbackDays)
+			if err != nil {
+				return nil, err
+			}
+			m.mu.Lock()
+			for key, datapoints := range fetched {
+				m.cache[key] = datapoints
+			}
+			m.mu.Unlock()
+		}
+	}
+
+	datapoints := make([][]types.Datapoint, len(queries))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, query := range queries {
+		datapoints[i] = m.cache[query.key()]
+	}
+	return datapoints, nil
+}
+
+// fetch runs a single batch of queries, following the pages of the result.
+func (m *Metrics) fetch(ctx context.Context, queries []MetricQuery, lookbackDays int) (map[string][]types.Datapoint, error) {
+	in := &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(m.now.AddDate(0, 0, -lookbackDays)),
+		EndTime:   aws.Time(m.now),
+		ScanBy:    types.ScanByTimestampAscending,
+	}
+	ids := map[string]MetricQuery{}
+	for i, query := range queries {
+		// Query ids must start with a lowercase letter.
+		id := fmt.Sprintf("q%d", i)
+		ids[id] = query
+		in.MetricDataQueries = append(in.MetricDataQueries, types.MetricDataQuery{
+			Id: aws.String(id),
+			MetricStat: &types.MetricStat{
+				Metric: &types.Metric{
+					Namespace:  aws.String(query.Namespace),
+					MetricName: aws.String(query.MetricName),
+					Dimensions: query.Dimensions,
+				},
+				Period: aws.Int32(query.Period),
+				Stat:   aws.String(string(query.Stat)),
+			},
+		})
+	}
+
+	fetched := map[string][]types.Datapoint{}
+	for _, query := range queries {
+		fetched[query.key()] = []types.Datapoint{}
+	}
+	paginator := cloudwatch.NewGetMetricDataPaginator(m.api, in)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range out.MetricDataResults {
+			query, ok := ids[aws.ToString(result.Id)]
+			if !ok {
+				continue
+			}
+			key := query.key()
+			for i, value := range result.Values {
+				if i >= len(result.Timestamps) {
+					break
+				}
+				fetched[key] = append(fetched[key], expandDatapoint(query.Stat, result.Timestamps[i], value))
+			}
+		}
+	}
+	return fetched, nil
+}
+
+func expandDatapoint(stat types.Statistic, timestamp time.Time, value float64) types.Datapoint {
+	datapoint := types.Datapoint{Timestamp: aws.Time(timestamp)}
+	switch stat {
+	case types.StatisticAverage:
+		datapoint.Average = aws.Float64(value)
+	case types.StatisticSum:
+		datapoint.Sum = aws.Float64(value)
+	case types.StatisticMaximum:
+		datapoint.Maximum = aws.Float64(value)
+	case types.StatisticMinimum:
+		datapoint.Minimum = aws.Float64(value)
+	case types.StatisticSampleCount:
+		datapoint.SampleCount = aws.Float64(value)
+	}
+	return datapoint
+}
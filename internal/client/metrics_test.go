@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// stubGetMetricData returns two datapoints for every query, split across two
+// pages, with the index of the call as the value.
+type stubGetMetricData struct {
+	calls []*cloudwatch.GetMetricDataInput
+}
+
+func (s *stubGetMetricData) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	s.calls = append(s.calls, params)
+	out := &cloudwatch.GetMetricDataOutput{}
+	if params.NextToken == nil {
+		out.NextToken = aws.String("page-2")
+	}
+	for _, query := range params.MetricDataQueries {
+		out.MetricDataResults = append(out.MetricDataResults, types.MetricDataResult{
+			Id:         query.Id,
+			Timestamps: []time.Time{aws.ToTime(params.StartTime)},
+			Values:     []float64{float64(len(s.calls))},
+		})
+	}
+	return out, nil
+}
+
+func volumeQuery(id string, lookbackDays int) MetricQuery {
+	return MetricQuery{
+		Namespace:  "AWS/EBS",
+		MetricName: "VolumeReadOps",
+		Dimensions: []types.Dimension{
+			{
+				Name:  aws.String("VolumeId"),
+				Value: aws.String(id),
+			},
+		},
+		Stat:         types.StatisticSum,
+		Period:       86400,
+		LookbackDays: lookbackDays,
+	}
+}
+
+func TestMetricsDatapoints(t *testing.T) {
+	now := time.Date(2023, 6, 12, 10, 0, 0, 0, time.UTC)
+	api := &stubGetMetricData{}
+	metrics := NewMetrics(api, now)
+
+	var queries []MetricQuery
+	for i := 0; i < MaxMetricDataQueries+1; i++ {
+		queries = append(queries, volumeQuery(fmt.Sprintf("vol-%d", i), 14))
+	}
+	datapoints, err := metrics.Datapoints(context.Background(), queries)
+	if err != nil {
+		t.Fatalf("Unexpected error fetching metrics: %s", err)
+	}
+
+	// Two batches of two pages each.
+	if len(api.calls) != 4 {
+		t.Fatalf("Metrics should be fetched with 4 calls, Got %d", len(api.calls))
+	}
+	if len(api.calls[0].MetricDataQueries) != MaxMetricDataQueries || len(api.calls[2].MetricDataQueries) != 1 {
+		t.Fatalf("Queries should be batched by %d, Got %d and %d", MaxMetricDataQueries, len(api.calls[0].MetricDataQueries), len(api.calls[2].MetricDataQueries))
+	}
+	if !aws.ToTime(api.calls[0].StartTime).Equal(now.AddDate(0, 0, -14)) || !aws.ToTime(api.calls[0].EndTime).Equal(now) {
+		t.Fatalf("Unexpected time range, Got %s to %s", aws.ToTime(api.calls[0].StartTime), aws.ToTime(api.calls[0].EndTime))
+	}
+
+	if len(datapoints) != len(queries) {
+		t.Fatalf("Datapoints should be returned for %d queries, Got %d", len(queries), len(datapoints))
+	}
+	if len(datapoints[0]) != 2 || aws.ToFloat64(datapoints[0][0].Sum) != 1 || aws.ToFloat64(datapoints[0][1].Sum) != 2 || datapoints[0][0].Average != nil {
+		t.Fatalf("Datapoints of both pages should be returned with the Sum set, Got %+v", datapoints[0])
+	}
+	if len(datapoints[MaxMetricDataQueries]) != 2 || aws.ToFloat64(datapoints[MaxMetricDataQueries][0].Sum) != 3 {
+		t.Fatalf("Datapoints of the second batch should be returned, Got %+v", datapoints[MaxMetricDataQueries])
+	}
+}
+
+func TestMetricsDatapoints_cached(t *testing.T) {
+	api := &stubGetMetricData{}
+	metrics := NewMetrics(api, time.Now())
+	ctx := context.Background()
+
+	if _, err := metrics.Datapoints(ctx, []MetricQuery{volumeQuery("vol-1", 14), volumeQuery("vol-1", 14)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.calls) != 2 || len(api.calls[0].MetricDataQueries) != 1 {
+		t.Fatalf("Duplicate queries should be fetched once, Got %d calls", len(api.calls))
+	}
+
+	datapoints, err := metrics.Datapoints(ctx, []MetricQuery{volumeQuery("vol-1", 14), volumeQuery("vol-1", 7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(api.calls) != 4 || len(api.calls[2].MetricDataQueries) != 1 {
+		t.Fatalf("Only queries that are not cached should be fetched, Got %d calls", len(api.calls))
+	}
+	if aws.ToFloat64(datapoints[0][0].Sum) != 1 || aws.ToFloat64(datapoints[1][0].Sum) != 3 {
+		t.Fatalf("Cached datapoints should be returned, Got %+v", datapoints)
+	}
+}
+
+func TestMetricsDatapoints_none(t *testing.T) {
+	api := &stubGetMetricData{}
+	datapoints, err := NewMetrics(api, time.Now()).Datapoints(context.Background(), nil)
+	if err != nil || len(datapoints) != 0 || len(api.calls) != 0 {
+		t.Fatalf("No queries should not call GetMetricData, Got %d calls", len(api.calls))
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const roleSessionName = "ckia"
+
+// Account identifies an aws account that checks are run against.
+type Account struct {
+	Id        string
+	Name      string
+	Partition string
+}
+
+// OrganizationAccounts returns every active account in the organization. The
+// client must belong to the management account or a delegated administrator.
+func OrganizationAccounts(ctx context.Context, conn AWSClient) ([]Account, error) {
+	var accounts []Account
+	paginator := organizations.NewListAccountsPaginator(conn.Organizations, &organizations.ListAccountsInput{})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range output.Accounts {
+			if account.Status != types.AccountStatusActive {
+				continue
+			}
+			accounts = append(accounts, Account{
+				Id:   aws.ToString(account.Id),
+				Name: aws.ToString(account.Name),
+			})
+		}
+	}
+
+	return accounts, nil
+}
+
+// AssumeRoleConfig returns a copy of cfg that uses credentials from assuming
+// roleArn with the credentials of cfg. externalId is optional.
+func AssumeRoleConfig(cfg aws.Config, roleArn string, externalId string) aws.Config {
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = roleSessionName
+		if externalId != "" {
+			o.ExternalID = aws.String(externalId)
+		}
+	})
+
+	assumedCfg := cfg.Copy()
+	assumedCfg.Credentials = aws.NewCredentialsCache(provider)
+
+	return assumedCfg
+}
+
+// RoleArn returns the arn of the role named roleName in the given account.
+func RoleArn(partition string, accountId string, roleName string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, accountId, roleName)
+}
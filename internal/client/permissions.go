@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// maxSimulatedActions is the number of actions simulated per call, to keep
+// requests small.
+const maxSimulatedActions = 50
+
+// SimulatePrincipalPolicyAPI is the IAM API used to simulate the policies of a
+// principal.
+type SimulatePrincipalPolicyAPI interface {
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+// GetRoleAPI is the IAM API used to look up the arn of a role.
+type GetRoleAPI interface {
+	GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+}
+
+// PrincipalArn returns the arn of the IAM user or role whose policies apply to
+// the caller arn returned by GetCallerIdentity. The root user is not an IAM
+// principal and returns an error, since every action is allowed to it. The
+// path of a role is not part of an assumed role arn, so roles are returned
+// without a path; use RoleArnWithPath to look it up.
+func PrincipalArn(callerArn string) (string, error) {
+	parsed, err := arn.Parse(callerArn)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case parsed.Service == "iam" && parsed.Resource == "root":
+		return "", fmt.Errorf("the root user (%s) is allowed every action and cannot be simulated", callerArn)
+	case parsed.Service == "iam":
+		return callerArn, nil
+	case parsed.Service == "sts" && strings.HasPrefix(parsed.Resource, "assumed-role/"):
+		parts := strings.Split(parsed.Resource, "/")
+		if len(parts) != 3 {
+			return "", fmt.Errorf("unexpected assumed role arn (%s)", callerArn)
+		}
+		return RoleArn(parsed.Partition, parsed.AccountID, parts[1]), nil
+	default:
+		return "", fmt.Errorf("policies of the caller (%s) cannot be simulated", callerArn)
+	}
+}
+
+// RoleArnWithPath returns the arn of the role principalArn, looked up with
+// GetRole so it includes the path of the role, e.g. /service-role/. Other
+// principals are returned unchanged.
+func RoleArnWithPath(ctx context.Context, api GetRoleAPI, principalArn string) (string, error) {
+	parsed, err := arn.Parse(principalArn)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return principalArn, nil
+	}
+	name := parsed.Resource[strings.LastIndex(parsed.Resource, "/")+1:]
+	out, err := api.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(name)})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.Role.Arn), nil
+}
+
+// DeniedActions simulates the policies of principalArn for every action and
+// returns the sorted actions that are not allowed on every resource.
+func DeniedActions(ctx context.Context, api SimulatePrincipalPolicyAPI, principalArn string, actions []string) ([]string, error) {
+	var denied []string
+	for start := 0; start < len(actions); start += maxSimulatedActions {
+		end := start + maxSimulatedActions
+		if end > len(actions) {
+			end = len(actions)
+		}
+		paginator := iam.NewSimulatePrincipalPolicyPaginator(api, &iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: aws.String(principalArn),
+			ActionNames:     actions[start:end],
+		})
+		for paginator.HasMorePages() {
+			out, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, result := range out.EvaluationResults {
+				if result.EvalDecision != types.PolicyEvaluationDecisionTypeAllowed {
+					denied = append(denied, aws.ToString(result.EvalActionName))
+				}
+			}
+		}
+	}
+	sort.Strings(denied)
+	return denied, nil
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// stubSimulatePrincipalPolicy denies the actions in denied and allows every
+// other action.
+type stubSimulatePrincipalPolicy struct {
+	denied []string
+	calls  []*iam.SimulatePrincipalPolicyInput
+}
+
+func (s *stubSimulatePrincipalPolicy) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	s.calls = append(s.calls, params)
+	out := &iam.SimulatePrincipalPolicyOutput{}
+	for _, action := range params.ActionNames {
+		decision := types.PolicyEvaluationDecisionTypeAllowed
+		for _, denied := range s.denied {
+			if action == denied {
+				decision = types.PolicyEvaluationDecisionTypeImplicitDeny
+			}
+		}
+		out.EvaluationResults = append(out.EvaluationResults, types.EvaluationResult{
+			EvalActionName: aws.String(action),
+			EvalDecision:   decision,
+		})
+	}
+	return out, nil
+}
+
+func TestPrincipalArn(t *testing.T) {
+	for callerArn, expected := range map[string]string{
+		"arn:aws:iam::123456789011:user/ckia":                              "arn:aws:iam::123456789011:user/ckia",
+		"arn:aws:sts::123456789011:assumed-role/ckia-readonly/session":     "arn:aws:iam::123456789011:role/ckia-readonly",
+		"arn:aws-us-gov:sts::123456789011:assumed-role/ckia-readonly/ckia": "arn:aws-us-gov:iam::123456789011:role/ckia-readonly",
+	} {
+		principalArn, err := PrincipalArn(callerArn)
+		if err != nil {
+			t.Fatalf("Unexpected error for caller (%s): %s", callerArn, err)
+		}
+		if principalArn != expected {
+			t.Fatalf("Principal of caller (%s) should be %s, Got %s", callerArn, expected, principalArn)
+		}
+	}
+
+	for _, callerArn := range []string{"arn:aws:iam::123456789011:root", "arn:aws:sts::123456789011:federated-user/ckia", "not-an-arn"} {
+		if _, err := PrincipalArn(callerArn); err == nil {
+			t.Fatalf("Expected an error for caller (%s)", callerArn)
+		}
+	}
+}
+
+// stubGetRole returns the roles in arns by name.
+type stubGetRole struct {
+	arns map[string]string
+}
+
+func (s *stubGetRole) GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	roleArn, ok := s.arns[aws.ToString(params.RoleName)]
+	if !ok {
+		return nil, errors.New("NoSuchEntity")
+	}
+	return &iam.GetRoleOutput{Role: &types.Role{Arn: aws.String(roleArn)}}, nil
+}
+
+func TestRoleArnWithPath(t *testing.T) {
+	api := &stubGetRole{arns: map[string]string{"ckia-readonly": "arn:aws:iam::123456789011:role/service-role/ckia-readonly"}}
+
+	for principalArn, expected := range map[string]string{
+		"arn:aws:iam::123456789011:role/ckia-readonly": "arn:aws:iam::123456789011:role/service-role/ckia-readonly",
+		"arn:aws:iam::123456789011:user/ckia":          "arn:aws:iam::123456789011:user/ckia",
+	} {
+		roleArn, err := RoleArnWithPath(context.Background(), api, principalArn)
+		if err != nil {
+			t.Fatalf("Unexpected error for principal (%s): %s", principalArn, err)
+		}
+		if roleArn != expected {
+			t.Fatalf("Principal (%s) should be %s, Got %s", principalArn, expected, roleArn)
+		}
+	}
+
+	if _, err := RoleArnWithPath(context.Background(), api, "arn:aws:iam::123456789011:role/unknown"); err == nil {
+		t.Fatal("Expected an error for a role that cannot be found")
+	}
+}
+
+func TestDeniedActions(t *testing.T) {
+	api := &stubSimulatePrincipalPolicy{denied: []string{"pricing:GetProducts", "ec2:Action48"}}
+	actions := []string{"pricing:GetProducts", "ec2:DescribeVolumes"}
+	for i := 0; i <= 48; i++ {
+		actions = append(actions, fmt.Sprintf("ec2:Action%d", i))
+	}
+
+	denied, err := DeniedActions(context.Background(), api, "arn:aws:iam::123456789011:user/ckia", actions)
+	if err != nil {
+		t.Fatalf("Unexpected error simulating actions: %s", err)
+	}
+	if len(api.calls) != 2 || aws.ToString(api.calls[0].PolicySourceArn) != "arn:aws:iam::123456789011:user/ckia" {
+		t.Fatalf("Actions should be simulated for the principal in batches of %d, Got %d calls", maxSimulatedActions, len(api.calls))
+	}
+	if expected := []string{"ec2:Action48", "pricing:GetProducts"}; !reflect.DeepEqual(denied, expected) {
+		t.Fatalf("Denied actions should be %v, Got %v", expected, denied)
+	}
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	// DefaultMaxAttempts is the most attempts of an API call when the aws
+	// config does not set max_attempts.
+	DefaultMaxAttempts = 10
+	// DefaultRequestsPerSecond is the rate of API calls allowed per service in
+	// each region before calls wait on the client.
+	DefaultRequestsPerSecond = 20
+)
+
+// rateLimits is shared by every client, so checks running at once against the
+// same service and region share a single rate limit.
+var rateLimits = NewRateLimits(DefaultRequestsPerSecond)
+
+// SetRateLimit sets the rate of API calls allowed per service in each region
+// for clients initiated afterwards. A rate of 0 or less disables the limit.
+func SetRateLimit(requestsPerSecond float64) {
+	rateLimits = NewRateLimits(requestsPerSecond)
+}
+
+// configureRetries returns cfg with the retry mode of the aws config, or the
+// adaptive retry mode, which backs off further once calls are throttled, when
+// retry_mode is not set, and middleware limiting the rate of calls and counting
+// them for the APIUsageCounter of the context.
+func configureRetries(cfg aws.Config, limits *RateLimits) aws.Config {
+	maxAttempts := cfg.RetryMaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	standardOptions := func(so *retry.StandardOptions) {
+		so.MaxAttempts = maxAttempts
+	}
+	if cfg.RetryMode == aws.RetryModeStandard {
+		cfg.Retryer = func() aws.Retryer {
+			return retry.NewStandard(standardOptions)
+		}
+	} else {
+		cfg.Retryer = func() aws.Retryer {
+			return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+				o.StandardOptions = append(o.StandardOptions, standardOptions)
+			})
+		}
+	}
+	cfg.APIOptions = append(append([]func(*middleware.Stack) error{}, cfg.APIOptions...), apiUsageMiddleware(limits))
+	return cfg
+}
+
+// apiUsageMiddleware counts every call and, after the retry middleware, waits
+// on the rate limit of the service before every attempt and counts attempts
+// and throttles.
+func apiUsageMiddleware(limits *RateLimits) func(*middleware.Stack) error {
+	throttles := retry.IsErrorThrottles(retry.DefaultThrottles)
+	return func(stack *middleware.Stack) error {
+		err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("ckiaAPIUsage", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if counter := apiUsageCounterFrom(ctx); counter != nil {
+				atomic.AddInt64(&counter.calls, 1)
+			}
+			return next.HandleInitialize(ctx, in)
+		}), middleware.Before)
+		if err != nil {
+			return err
+		}
+
+		return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("ckiaRateLimit", func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if err := limits.Wait(ctx, awsmiddleware.GetServiceID(ctx), awsmiddleware.GetRegion(ctx)); err != nil {
+				return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+			}
+			out, metadata, err := next.HandleFinalize(ctx, in)
+			if counter := apiUsageCounterFrom(ctx); counter != nil {
+				atomic.AddInt64(&counter.attempts, 1)
+				if err != nil && throttles.IsErrorThrottle(err) == aws.TrueTernary {
+					atomic.AddInt64(&counter.throttles, 1)
+				}
+			}
+			return out, metadata, err
+		}), "Retry", middleware.After)
+	}
+}
+
+// APIUsageCounter counts the AWS API calls made with a context returned by
+// WithAPIUsageCounter.
+type APIUsageCounter struct {
+	calls     int64
+	attempts  int64
+	throttles int64
+}
+
+type apiUsageCounterKey struct{}
+
+// WithAPIUsageCounter returns a context counting the AWS API calls made with it
+// in the returned counter.
+func WithAPIUsageCounter(ctx context.Context) (context.Context, *APIUsageCounter) {
+	counter := &APIUsageCounter{}
+	return context.WithValue(ctx, apiUsageCounterKey{}, counter), counter
+}
+
+func apiUsageCounterFrom(ctx context.Context) *APIUsageCounter {
+	counter, _ := ctx.Value(apiUsageCounterKey{}).(*APIUsageCounter)
+	return counter
+}
+
+// Usage returns the calls counted so far. Every attempt of a call after the
+// first is a retry.
+func (c *APIUsageCounter) Usage() common.APIUsage {
+	calls := int(atomic.LoadInt64(&c.calls))
+	retries := int(atomic.LoadInt64(&c.attempts)) - calls
+	if retries < 0 {
+		retries = 0
+	}
+	return common.APIUsage{
+		Calls:     calls,
+		Retries:   retries,
+		Throttles: int(atomic.LoadInt64(&c.throttles)),
+	}
+}
+
+// RateLimits holds a token bucket per service and region allowing
+// requestsPerSecond calls per second, with bursts of up to a second of calls.
+type RateLimits struct {
+	requestsPerSecond float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewRateLimits returns RateLimits allowing requestsPerSecond calls per second
+// for each service and region. A rate of 0 or less allows every call.
+func NewRateLimits(requestsPerSecond float64) *RateLimits {
+	return &RateLimits{
+		requestsPerSecond: requestsPerSecond,
+		buckets:           map[string]*bucket{},
+	}
+}
+
+// Wait blocks until a call to the service in the region is allowed or the
+// context is done.
+func (l *RateLimits) Wait(ctx context.Context, service string, region string) error {
+	if l == nil || l.requestsPerSecond <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	key := service + "|" + region
+	b, ok := l.buckets[key]
+	if !ok {
+		b = newBucket(l.requestsPerSecond)
+		l.buckets[key] = b
+	}
+	l.mu.Unlock()
+
+	delay := b.reserve(time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// bucket spaces calls interval apart, allowing a burst of calls after being
+// idle.
+type bucket struct {
+	interval time.Duration
+	burst    time.Duration
+
+	mu sync.Mutex
+	// next is the time the next call is allowed at without a burst.
+	next time.Time
+}
+
+func newBucket(requestsPerSecond float64) *bucket {
+	burst := requestsPerSecond
+	if burst < 1 {
+		burst = 1
+	}
+	interval := time.Duration(float64(time.Second) / requestsPerSecond)
+	return &bucket{
+		interval: interval,
+		burst:    time.Duration(burst * float64(interval)),
+	}
+}
+
+// reserve reserves a call and returns how long to wait from now before making
+// it.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if earliest := now.Add(-b.burst); b.next.Before(earliest) {
+		b.next = earliest
+	}
+	b.next = b.next.Add(b.interval)
+	return b.next.Sub(now)
+}
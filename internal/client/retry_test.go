@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	throttlingResponse = `<ErrorResponse><Error><Type>Sender</Type><Code>Throttling</Code><Message>Rate exceeded</Message></Error><RequestId>1</RequestId></ErrorResponse>`
+	callerResponse     = `<GetCallerIdentityResponse><GetCallerIdentityResult><Arn>arn:aws:iam::123456789011:user/ckia</Arn><UserId>AIDA</UserId><Account>123456789011</Account></GetCallerIdentityResult><ResponseMetadata><RequestId>2</RequestId></ResponseMetadata></GetCallerIdentityResponse>`
+)
+
+// stubHTTPClient throttles the first throttled requests it receives and
+// returns the caller identity for every other request.
+type stubHTTPClient struct {
+	throttled int
+	requests  int
+}
+
+func (c *stubHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests++
+	status, body := http.StatusOK, callerResponse
+	if c.requests <= c.throttled {
+		status, body = http.StatusBadRequest, throttlingResponse
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func testConfig(httpClient aws.HTTPClient) aws.Config {
+	return aws.Config{
+		Region:     "us-east-1",
+		HTTPClient: httpClient,
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+}
+
+func TestConfigureRetries(t *testing.T) {
+	retryer, ok := configureRetries(aws.Config{}, nil).Retryer().(*retry.AdaptiveMode)
+	if !ok {
+		t.Fatal("Clients should retry in the adaptive retry mode")
+	}
+	if retryer.MaxAttempts() != DefaultMaxAttempts {
+		t.Fatalf("Max attempts should default to %d, Got %d", DefaultMaxAttempts, retryer.MaxAttempts())
+	}
+
+	if attempts := configureRetries(aws.Config{RetryMaxAttempts: 4}, nil).Retryer().MaxAttempts(); attempts != 4 {
+		t.Fatalf("Max attempts of the aws config should be used, Got %d", attempts)
+	}
+
+	standard, ok := configureRetries(aws.Config{RetryMode: aws.RetryModeStandard, RetryMaxAttempts: 4}, nil).Retryer().(*retry.Standard)
+	if !ok {
+		t.Fatal("Clients should retry in the standard retry mode when the aws config sets it")
+	}
+	if standard.MaxAttempts() != 4 {
+		t.Fatalf("Max attempts of the aws config should be used, Got %d", standard.MaxAttempts())
+	}
+	if _, ok := configureRetries(aws.Config{RetryMode: aws.RetryModeAdaptive}, nil).Retryer().(*retry.AdaptiveMode); !ok {
+		t.Fatal("Clients should retry in the adaptive retry mode when the aws config sets it")
+	}
+}
+
+func TestAPIUsageCounter(t *testing.T) {
+	httpClient := &stubHTTPClient{throttled: 2}
+	conn := sts.NewFromConfig(configureRetries(testConfig(httpClient), NewRateLimits(0)), func(o *sts.Options) {
+		// Retry without delay to keep the test fast.
+		o.Retryer = retry.NewStandard(func(so *retry.StandardOptions) {
+			so.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+		})
+	})
+
+	ctx, counter := WithAPIUsageCounter(context.Background())
+	for i := 0; i < 2; i++ {
+		if _, err := conn.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+			t.Fatalf("Unexpected error calling the API: %s", err)
+		}
+	}
+	if _, err := conn.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{}); err != nil {
+		t.Fatalf("Unexpected error calling the API: %s", err)
+	}
+
+	usage := counter.Usage()
+	if usage.Calls != 2 || usage.Retries != 2 || usage.Throttles != 2 {
+		t.Fatalf("Expected 2 calls, 2 retries and 2 throttles, Got %+v", usage)
+	}
+	if httpClient.requests != 5 {
+		t.Fatalf("Expected 5 requests, Got %d", httpClient.requests)
+	}
+}
+
+func TestBucketReserve(t *testing.T) {
+	now := time.Date(2023, 6, 12, 10, 0, 0, 0, time.UTC)
+	b := newBucket(2)
+
+	for i, expected := range []time.Duration{-500 * time.Millisecond, 0, 500 * time.Millisecond, time.Second} {
+		if delay := b.reserve(now); delay != expected {
+			t.Fatalf("Call %d should wait %s, Got %s", i, expected, delay)
+		}
+	}
+	if delay := b.reserve(now.Add(time.Minute)); delay > 0 {
+		t.Fatalf("Calls after being idle should not wait, Got %s", delay)
+	}
+}
+
+func TestRateLimitsWait(t *testing.T) {
+	limits := NewRateLimits(1)
+	ctx := context.Background()
+	if err := limits.Wait(ctx, "EC2", "us-east-1"); err != nil {
+		t.Fatalf("The first call should not wait, Got %s", err)
+	}
+	if err := limits.Wait(ctx, "EC2", "us-west-2"); err != nil {
+		t.Fatalf("Calls in another region should not share the limit, Got %s", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limits.Wait(canceled, "EC2", "us-east-1"); err != context.Canceled {
+		t.Fatalf("Waiting on the limit should stop once the context is done, Got %v", err)
+	}
+
+	if err := NewRateLimits(0).Wait(canceled, "EC2", "us-east-1"); err != nil {
+		t.Fatalf("Calls should not wait without a limit, Got %s", err)
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	rdsTypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// elbv2TagsBatchSize is the maximum number of resources of a DescribeTags call.
+const elbv2TagsBatchSize = 20
+
+// EC2Tags converts ec2 resource tags into a map of tag key to value.
+func EC2Tags(tags []ec2Types.Tag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		m[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return m
+}
+
+// RDSTags converts rds resource tags into a map of tag key to value.
+func RDSTags(tags []rdsTypes.Tag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		m[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return m
+}
+
+// ELBv2Tags returns the tags of elbv2 resources as a map of tag key to value by
+// resource arn. Resources without tags are left out.
+func ELBv2Tags(ctx context.Context, api ELBv2API, arns []string) (map[string]map[string]string, error) {
+	tags := map[string]map[string]string{}
+	for start := 0; start < len(arns); start += elbv2TagsBatchSize {
+		end := start + elbv2TagsBatchSize
+		if end > len(arns) {
+			end = len(arns)
+		}
+		out, err := api.DescribeTags(ctx, &elasticloadbalancingv2.DescribeTagsInput{
+			ResourceArns: arns[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, description := range out.TagDescriptions {
+			if len(description.Tags) == 0 {
+				continue
+			}
+			m := make(map[string]string, len(description.Tags))
+			for _, tag := range description.Tags {
+				m[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			tags[aws.ToString(description.ResourceArn)] = m
+		}
+	}
+	return tags, nil
+}
+
+// TagFilter selects the resources evaluated by checks by their tags. A resource
+// is evaluated when it matches every filter and none of the exclusions. The
+// zero TagFilter evaluates every resource.
+type TagFilter struct {
+	filters    []tagCondition
+	exclusions []tagCondition
+}
+
+// tagCondition matches resources with the tag key, and with the tag value when
+// value is set. A negated condition matches every other resource.
+type tagCondition struct {
+	key      string
+	value    string
+	hasValue bool
+	negated  bool
+}
+
+// ParseTagFilter parses tag filters and exclusions into a TagFilter. A filter
+// is either key=value, key for resources with the tag, !key for resources
+// without the tag or key!=value for resources without the tag value. An
+// exclusion is either key or key=value.
+func ParseTagFilter(filters []string, exclusions []string) (TagFilter, error) {
+	var f TagFilter
+	for _, filter := range filters {
+		condition, err := parseTagCondition(filter, true)
+		if err != nil {
+			return TagFilter{}, fmt.Errorf("invalid tag filter (%s): %w", filter, err)
+		}
+		f.filters = append(f.filters, condition)
+	}
+	for _, exclusion := range exclusions {
+		condition, err := parseTagCondition(exclusion, false)
+		if err != nil {
+			return TagFilter{}, fmt.Errorf("invalid tag exclusion (%s): %w", exclusion, err)
+		}
+		f.exclusions = append(f.exclusions, condition)
+	}
+	return f, nil
+}
+
+func parseTagCondition(expression string, allowNegation bool) (tagCondition, error) {
+	var condition tagCondition
+	expression = strings.TrimSpace(expression)
+	if key, value, ok := strings.Cut(expression, "!="); ok {
+		condition = tagCondition{key: key, value: value, hasValue: true, negated: true}
+	} else if strings.HasPrefix(expression, "!") {
+		condition = tagCondition{key: expression[1:], negated: true}
+	} else {
+		key, value, hasValue := strings.Cut(expression, "=")
+		condition = tagCondition{key: key, value: value, hasValue: hasValue}
+	}
+
+	if condition.negated && !allowNegation {
+		return tagCondition{}, errors.New("negation is only supported by tag filters")
+	}
+	if condition.key == "" {
+		return tagCondition{}, errors.New("a tag key is required")
+	}
+	return condition, nil
+}
+
+func (c tagCondition) matches(tags map[string]string) bool {
+	value, ok := tags[c.key]
+	matched := ok && (!c.hasValue || value == c.value)
+	return matched != c.negated
+}
+
+// Match returns true when a resource with tags should be evaluated.
+func (f TagFilter) Match(tags map[string]string) bool {
+	for _, condition := range f.filters {
+		if !condition.matches(tags) {
+			return false
+		}
+	}
+	for _, condition := range f.exclusions {
+		if condition.matches(tags) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package client
+
+import "testing"
+
+func TestTagFilterMatch(t *testing.T) {
+	owned := map[string]string{"owner": "platform", "cost-center": "1234"}
+	other := map[string]string{"owner": "data"}
+
+	cases := []struct {
+		filters    []string
+		exclusions []string
+		tags       map[string]string
+		want       bool
+	}{
+		{nil, nil, nil, true},
+		{[]string{"owner=platform"}, nil, owned, true},
+		{[]string{"owner=platform"}, nil, other, false},
+		{[]string{"cost-center"}, nil, owned, true},
+		{[]string{"cost-center"}, nil, other, false},
+		{[]string{"!cost-center"}, nil, other, true},
+		{[]string{"!cost-center"}, nil, owned, false},
+		{[]string{"owner!=data"}, nil, owned, true},
+		{[]string{"owner!=data"}, nil, other, false},
+		{[]string{"owner!=data"}, nil, nil, true},
+		{[]string{"owner", "cost-center=1234"}, nil, owned, true},
+		{[]string{"owner", "cost-center=5678"}, nil, owned, false},
+		{nil, []string{"cost-center"}, owned, false},
+		{nil, []string{"owner=platform"}, other, true},
+		{[]string{"owner"}, []string{"owner=data"}, other, false},
+	}
+	for _, c := range cases {
+		filter, err := ParseTagFilter(c.filters, c.exclusions)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %v and %v: %s", c.filters, c.exclusions, err)
+		}
+		if got := filter.Match(c.tags); got != c.want {
+			t.Fatalf("Filters %v and exclusions %v on %v should be %t, Got %t", c.filters, c.exclusions, c.tags, c.want, got)
+		}
+	}
+}
+
+func TestParseTagFilter_invalid(t *testing.T) {
+	for _, filters := range [][]string{{""}, {"=platform"}, {"!"}, {"!=platform"}} {
+		if _, err := ParseTagFilter(filters, nil); err == nil {
+			t.Fatalf("Expected an error for filters %v", filters)
+		}
+	}
+	if _, err := ParseTagFilter(nil, []string{"!owner"}); err == nil {
+		t.Fatal("Expected an error for a negated exclusion")
+	}
+}
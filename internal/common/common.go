@@ -3,17 +3,40 @@ package common
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
-	"reflect"
+	"math"
 )
 
 type Check struct {
 	Id                  string `json:"id"`
+	Category            string `json:"category"`
+	Severity            string `json:"severity"`
 	Name                string `json:"name"`
 	Description         string `json:"description"`
 	Criteria            string `json:"criteria"`
 	RecommendedAction   string `json:"recommendedAction"`
 	AdditionalResources string `json:"additionalResources"`
+	// Global checks evaluate account wide resources and only run once per
+	// account instead of once per region.
+	Global bool `json:"global,omitempty"`
+	// Untagged checks report findings without resource tags, e.g. for account
+	// settings or quotas, so tag based suppressions never match them.
+	Untagged bool `json:"untagged,omitempty"`
+	// Permissions are the IAM actions the check calls, e.g.
+	// ec2:DescribeVolumes.
+	Permissions []string `json:"permissions,omitempty"`
+	// Parameters are the thresholds of the check that can be configured
+	// through the checks section of the config file.
+	Parameters []Parameter `json:"parameters,omitempty"`
+}
+
+// Result is the value returned from running a check. Every check result embeds
+// a Check and a CheckResult, so the metadata of the check that produced it and
+// the uniform outcome of the run are always available.
+type Result interface {
+	Metadata() Check
+	Summary() CheckResult
+	Suppress(severity string, suppress func(Finding) (SuppressedFinding, bool))
+	SetAPIUsage(usage APIUsage)
 }
 
 func PrettyString(str string) (string, error) {
@@ -24,21 +47,6 @@ func PrettyString(str string) (string, error) {
 	return prettyJSON.String(), nil
 }
 
-func Call(funcName string, checksMap map[string]interface{}, method string, params ...interface{}) (result interface{}, err error) {
-	f := reflect.ValueOf(checksMap[funcName]).MethodByName(method)
-	if len(params) != f.Type().NumIn() {
-		err = errors.New("the number of params is out of index")
-		return
-	}
-	in := make([]reflect.Value, len(params))
-	for k, param := range params {
-		in[k] = reflect.ValueOf(param)
-	}
-	res := f.Call(in)
-	result = res[0].Interface()
-	return
-}
-
 func StringSliceContains(s []string, str string) bool {
 	for _, v := range s {
 		if v == str {
@@ -47,4 +55,9 @@ func StringSliceContains(s []string, str string) bool {
 	}
 
 	return false
-}
\ No newline at end of file
+}
+
+// RoundCents rounds a dollar amount to the nearest cent.
+func RoundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -1,6 +1,25 @@
 package common
 
 const (
-	MethodNameRun  = "Run"
-	MethodNameList = "List"
+	CategoryCostOptimization = "costOptimization"
+	CategoryPerformance      = "performance"
+	CategorySecurity         = "security"
+	CategoryFaultTolerance   = "faultTolerance"
+	CategoryServiceLimits    = "serviceLimits"
+)
+
+// Check result statuses mirror the Trusted Advisor green, yellow and red model.
+const (
+	StatusOk            = "ok"
+	StatusWarning       = "warning"
+	StatusError         = "error"
+	StatusNotApplicable = "not_applicable"
+	StatusFailedToRun   = "failed_to_run"
+)
+
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
 )
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Parameter describes a configurable threshold of a check and its default.
+type Parameter struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Default     float64 `json:"default"`
+	// Integer parameters, e.g. day counts, reject fractional values.
+	Integer bool `json:"integer,omitempty"`
+	// Min is the lowest accepted value, e.g. 1 for day counts where 0 would
+	// evaluate no metrics at all.
+	Min float64 `json:"min,omitempty"`
+	// AtMost names another parameter of the check this one must not exceed,
+	// e.g. idleDays must fit within lookbackDays.
+	AtMost string `json:"atMost,omitempty"`
+}
+
+// Parameters are the effective values of the parameters of a check, keyed by
+// parameter name.
+type Parameters map[string]float64
+
+func (p Parameters) Int(name string) int {
+	return int(p[name])
+}
+
+func (p Parameters) Float(name string) float64 {
+	return p[name]
+}
+
+// ShortName returns the last segment of the check id, which is used to
+// configure the check, e.g. IdleDBInstances for ckia:aws:cost:IdleDBInstances.
+func (c Check) ShortName() string {
+	return c.Id[strings.LastIndex(c.Id, ":")+1:]
+}
+
+// DefaultParameters returns the default value of every parameter of the check.
+func (c Check) DefaultParameters() Parameters {
+	params := Parameters{}
+	for _, parameter := range c.Parameters {
+		params[parameter.Name] = parameter.Default
+	}
+	return params
+}
+
+// Describe returns the check with its description and criteria set by
+// describe, so they state the parameters the check runs with. When params is
+// nil, e.g. for a check that has not run yet, the defaults are described.
+func (c Check) Describe(params Parameters, describe func(check *Check, params Parameters)) Check {
+	if params == nil {
+		params = c.DefaultParameters()
+	}
+	describe(&c, params)
+	return c
+}
+
+// ResolveParameters merges overrides into the default parameters of the check.
+// Parameter names are matched case insensitively since configuration keys may
+// be lower cased. Unknown parameters, non numeric values, values below their
+// Min, fractional values of integer parameters and values above their AtMost
+// parameter are an error.
+func (c Check) ResolveParameters(overrides map[string]interface{}) (Parameters, error) {
+	params := c.DefaultParameters()
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		parameter, ok := c.parameter(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter (%s) for check (%s)", name, c.ShortName())
+		}
+		value, ok := toFloat(overrides[name])
+		if !ok {
+			return nil, fmt.Errorf("parameter (%s) for check (%s) must be a number", parameter.Name, c.ShortName())
+		}
+		if value < 0 {
+			return nil, fmt.Errorf("parameter (%s) for check (%s) must not be negative", parameter.Name, c.ShortName())
+		}
+		if value < parameter.Min {
+			return nil, fmt.Errorf("parameter (%s) for check (%s) must be at least %g", parameter.Name, c.ShortName(), parameter.Min)
+		}
+		if parameter.Integer && value != math.Trunc(value) {
+			return nil, fmt.Errorf("parameter (%s) for check (%s) must be an integer", parameter.Name, c.ShortName())
+		}
+		params[parameter.Name] = value
+	}
+
+	for _, parameter := range c.Parameters {
+		if parameter.AtMost != "" && params[parameter.Name] > params[parameter.AtMost] {
+			return nil, fmt.Errorf("parameter (%s) for check (%s) must not be greater than %s", parameter.Name, c.ShortName(), parameter.AtMost)
+		}
+	}
+	return params, nil
+}
+
+func (c Check) parameter(name string) (Parameter, bool) {
+	for _, parameter := range c.Parameters {
+		if strings.EqualFold(parameter.Name, name) {
+			return parameter, true
+		}
+	}
+	return Parameter{}, false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+)
+
+func testCheck() Check {
+	return Check{
+		Id: "ckia:aws:cost:IdleDBInstances",
+		Parameters: []Parameter{
+			{Name: "lookbackDays", Default: 14},
+			{Name: "idleDays", Default: 7},
+		},
+	}
+}
+
+func TestCheckShortName(t *testing.T) {
+	if name := testCheck().ShortName(); name != "IdleDBInstances" {
+		t.Fatalf(`ShortName should be IdleDBInstances, Got %s`, name)
+	}
+}
+
+func TestCheckResolveParameters_defaults(t *testing.T) {
+	params, err := testCheck().ResolveParameters(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params.Int("lookbackDays") != 14 || params.Int("idleDays") != 7 {
+		t.Fatalf(`Default parameters not applied, Got %v`, params)
+	}
+}
+
+func TestCheckResolveParameters_overrides(t *testing.T) {
+	params, err := testCheck().ResolveParameters(map[string]interface{}{"lookbackdays": 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params.Int("lookbackDays") != 30 {
+		t.Fatalf(`lookbackDays should be 30, Got %d`, params.Int("lookbackDays"))
+	}
+	if params.Int("idleDays") != 7 {
+		t.Fatalf(`idleDays should be 7, Got %d`, params.Int("idleDays"))
+	}
+}
+
+func TestCheckResolveParameters_unknown(t *testing.T) {
+	if _, err := testCheck().ResolveParameters(map[string]interface{}{"lookback": 30}); err == nil {
+		t.Fatal(`Unknown parameter should return an error`)
+	}
+}
+
+func TestCheckResolveParameters_notNumber(t *testing.T) {
+	if _, err := testCheck().ResolveParameters(map[string]interface{}{"lookbackDays": "thirty"}); err == nil {
+		t.Fatal(`Non numeric parameter should return an error`)
+	}
+}
+
+func TestCheckResolveParameters_integer(t *testing.T) {
+	check := testCheck()
+	check.Parameters[0].Integer = true
+	if _, err := check.ResolveParameters(map[string]interface{}{"lookbackDays": 7.5}); err == nil {
+		t.Fatal(`Fractional integer parameter should return an error`)
+	}
+	if _, err := check.ResolveParameters(map[string]interface{}{"lookbackDays": 30.0}); err != nil {
+		t.Fatalf(`Whole float integer parameter should be accepted, Got %s`, err)
+	}
+}
+
+func TestCheckResolveParameters_atMost(t *testing.T) {
+	check := testCheck()
+	check.Parameters[1].AtMost = "lookbackDays"
+	if _, err := check.ResolveParameters(map[string]interface{}{"idleDays": 21}); err == nil {
+		t.Fatal(`idleDays greater than lookbackDays should return an error`)
+	}
+	if _, err := check.ResolveParameters(map[string]interface{}{"lookbackDays": 5}); err == nil {
+		t.Fatal(`lookbackDays less than the default idleDays should return an error`)
+	}
+	if _, err := check.ResolveParameters(map[string]interface{}{"lookbackDays": 21, "idleDays": 21}); err != nil {
+		t.Fatalf(`idleDays equal to lookbackDays should be accepted, Got %s`, err)
+	}
+}
+
+func TestCheckResolveParameters_min(t *testing.T) {
+	check := testCheck()
+	check.Parameters[0].Min = 1
+	if _, err := check.ResolveParameters(map[string]interface{}{"lookbackDays": 0}); err == nil {
+		t.Fatal(`lookbackDays below its min should return an error`)
+	}
+	if _, err := check.ResolveParameters(map[string]interface{}{"idleDays": 0}); err != nil {
+		t.Fatalf(`0 should be accepted for a parameter without a min, Got %s`, err)
+	}
+}
+
+func TestCheckDescribe_defaults(t *testing.T) {
+	describe := func(check *Check, params Parameters) {
+		check.Criteria = fmt.Sprintf("idle for %d days", params.Int("idleDays"))
+	}
+
+	if check := testCheck().Describe(nil, describe); check.Criteria != "idle for 7 days" {
+		t.Fatalf(`Criteria should describe the default idleDays, Got %s`, check.Criteria)
+	}
+	if check := testCheck().Describe(Parameters{"idleDays": 3}, describe); check.Criteria != "idle for 3 days" {
+		t.Fatalf(`Criteria should describe the given idleDays, Got %s`, check.Criteria)
+	}
+}
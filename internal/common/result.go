@@ -0,0 +1,122 @@
+package common
+
+// Finding is a single resource flagged by a check.
+type Finding struct {
+	ResourceId  string            `json:"resourceId"`
+	ResourceArn string            `json:"resourceArn,omitempty"`
+	AccountId   string            `json:"accountId"`
+	AccountName string            `json:"accountName,omitempty"`
+	Region      string            `json:"region,omitempty"`
+	Reason      string            `json:"reason"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	// EstimatedMonthlySavings is the on demand cost in USD saved by acting on
+	// the finding. Only set by cost optimization checks.
+	EstimatedMonthlySavings float64 `json:"estimatedMonthlySavings,omitempty"`
+}
+
+// CheckResult is the outcome of a single check run shared by every check. It is
+// embedded in each check alongside the check specific detail.
+type CheckResult struct {
+	AccountId               string     `json:"accountId"`
+	Region                  string     `json:"region,omitempty"`
+	Status                  string     `json:"status"`
+	ResourcesEvaluated      int        `json:"resourcesEvaluated"`
+	ResourcesFlagged        int        `json:"resourcesFlagged"`
+	EstimatedMonthlySavings float64    `json:"estimatedMonthlySavings,omitempty"`
+	EffectiveParameters     Parameters `json:"effectiveParameters,omitempty"`
+	Findings                []Finding  `json:"findings"`
+	// Suppressed are findings excluded from the result by a suppression rule or
+	// a baseline. They are not counted as flagged resources.
+	Suppressed []SuppressedFinding `json:"suppressed,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	// APIUsage counts the AWS API calls made by the check run.
+	APIUsage *APIUsage `json:"apiUsage,omitempty"`
+}
+
+// APIUsage counts the AWS API calls of a check run, so checks that are heavy
+// on the API or that were throttled can be identified.
+type APIUsage struct {
+	Calls     int `json:"calls"`
+	Retries   int `json:"retries"`
+	Throttles int `json:"throttles"`
+}
+
+// SuppressedFinding is a finding that was suppressed and why.
+type SuppressedFinding struct {
+	Finding
+	// SuppressedBy is either SuppressedBySuppression or SuppressedByBaseline.
+	SuppressedBy      string `json:"suppressedBy"`
+	SuppressionReason string `json:"suppressionReason,omitempty"`
+}
+
+const (
+	SuppressedBySuppression = "suppression"
+	SuppressedByBaseline    = "baseline"
+)
+
+// NewCheckResult returns an empty result for a check run in the given account
+// and region with the parameters it was run with. Global checks should pass an
+// empty region.
+func NewCheckResult(accountId string, region string, params Parameters) CheckResult {
+	return CheckResult{
+		AccountId:           accountId,
+		Region:              region,
+		EffectiveParameters: params,
+		Findings:            []Finding{},
+	}
+}
+
+func (r CheckResult) Summary() CheckResult {
+	return r
+}
+
+// SetAPIUsage records the AWS API calls made by the check run on the result.
+func (r *CheckResult) SetAPIUsage(usage APIUsage) {
+	r.APIUsage = &usage
+}
+
+// AddFinding records a flagged resource on the result.
+func (r *CheckResult) AddFinding(finding Finding) {
+	r.Findings = append(r.Findings, finding)
+	r.ResourcesFlagged = len(r.Findings)
+	r.EstimatedMonthlySavings = RoundCents(r.EstimatedMonthlySavings + finding.EstimatedMonthlySavings)
+}
+
+// Evaluate sets the status of the result from the number of resources evaluated
+// and flagged. Flagged resources of a high or critical severity check are an
+// error, anything else flagged is a warning.
+func (r *CheckResult) Evaluate(severity string) {
+	r.ResourcesFlagged = len(r.Findings)
+	switch {
+	case r.ResourcesEvaluated == 0:
+		r.Status = StatusNotApplicable
+	case r.ResourcesFlagged == 0:
+		r.Status = StatusOk
+	case severity == SeverityHigh || severity == SeverityCritical:
+		r.Status = StatusError
+	default:
+		r.Status = StatusWarning
+	}
+}
+
+// Suppress moves every finding for which suppress returns true into the
+// suppressed findings of the result, then re-evaluates the flagged resources,
+// savings and status of the result with the remaining findings. Results of
+// check runs that failed to run are left unchanged.
+func (r *CheckResult) Suppress(severity string, suppress func(Finding) (SuppressedFinding, bool)) {
+	if r.Status == StatusFailedToRun {
+		return
+	}
+	findings := r.Findings
+	r.Findings = []Finding{}
+	r.EstimatedMonthlySavings = 0
+	for _, finding := range findings {
+		if suppressed, ok := suppress(finding); ok {
+			r.Suppressed = append(r.Suppressed, suppressed)
+			continue
+		}
+		r.AddFinding(finding)
+	}
+	r.Evaluate(severity)
+}
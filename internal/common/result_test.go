@@ -0,0 +1,86 @@
+package common
+
+import "testing"
+
+func TestCheckResultEvaluate_notApplicable(t *testing.T) {
+	result := NewCheckResult("123456789011", "us-east-1", nil)
+	result.Evaluate(SeverityLow)
+
+	if result.Status != StatusNotApplicable {
+		t.Fatalf(`Status should be %s, Got %s`, StatusNotApplicable, result.Status)
+	}
+}
+
+func TestCheckResultEvaluate_ok(t *testing.T) {
+	result := NewCheckResult("123456789011", "us-east-1", nil)
+	result.ResourcesEvaluated = 3
+	result.Evaluate(SeverityLow)
+
+	if result.Status != StatusOk {
+		t.Fatalf(`Status should be %s, Got %s`, StatusOk, result.Status)
+	}
+}
+
+func TestCheckResultEvaluate_warning(t *testing.T) {
+	result := NewCheckResult("123456789011", "us-east-1", nil)
+	result.ResourcesEvaluated = 3
+	result.AddFinding(Finding{ResourceId: "vol-02e71c945942481e85"})
+	result.Evaluate(SeverityMedium)
+
+	if result.Status != StatusWarning {
+		t.Fatalf(`Status should be %s, Got %s`, StatusWarning, result.Status)
+	}
+	if result.ResourcesFlagged != 1 {
+		t.Fatalf(`ResourcesFlagged should be 1, Got %d`, result.ResourcesFlagged)
+	}
+}
+
+func TestCheckResultEvaluate_error(t *testing.T) {
+	result := NewCheckResult("123456789011", "", nil)
+	result.ResourcesEvaluated = 1
+	result.AddFinding(Finding{ResourceId: "123456789011"})
+	result.Evaluate(SeverityCritical)
+
+	if result.Status != StatusError {
+		t.Fatalf(`Status should be %s, Got %s`, StatusError, result.Status)
+	}
+}
+
+func TestCheckResultSuppress(t *testing.T) {
+	result := NewCheckResult("123456789011", "us-east-1", nil)
+	result.ResourcesEvaluated = 2
+	result.AddFinding(Finding{ResourceId: "eipalloc-01", EstimatedMonthlySavings: 3.6})
+	result.AddFinding(Finding{ResourceId: "eipalloc-02", EstimatedMonthlySavings: 3.6})
+	result.Evaluate(SeverityLow)
+
+	result.Suppress(SeverityLow, func(finding Finding) (SuppressedFinding, bool) {
+		if finding.ResourceId != "eipalloc-01" {
+			return SuppressedFinding{}, false
+		}
+		return SuppressedFinding{Finding: finding, SuppressedBy: SuppressedBySuppression, SuppressionReason: "partner allowlist"}, true
+	})
+
+	if result.ResourcesFlagged != 1 {
+		t.Fatalf(`ResourcesFlagged should be 1, Got %d`, result.ResourcesFlagged)
+	}
+	if result.EstimatedMonthlySavings != 3.6 {
+		t.Fatalf(`EstimatedMonthlySavings should be 3.6, Got %f`, result.EstimatedMonthlySavings)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].ResourceId != "eipalloc-01" {
+		t.Fatalf(`Suppressed should only contain eipalloc-01, Got %v`, result.Suppressed)
+	}
+	if result.Status != StatusWarning {
+		t.Fatalf(`Status should be %s, Got %s`, StatusWarning, result.Status)
+	}
+
+	result.Suppress(SeverityLow, func(finding Finding) (SuppressedFinding, bool) {
+		return SuppressedFinding{Finding: finding, SuppressedBy: SuppressedByBaseline}, true
+	})
+
+	if result.Status != StatusOk {
+		t.Fatalf(`Status should be %s, Got %s`, StatusOk, result.Status)
+	}
+	if len(result.Suppressed) != 2 {
+		t.Fatalf(`Suppressed should contain 2 findings, Got %d`, len(result.Suppressed))
+	}
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// StoredResult is a check result with only the metadata of the check and the
+// common result, such as a result read back from the json output of a check
+// run, where the check specific detail is dropped.
+type StoredResult struct {
+	Check
+	CheckResult
+}
+
+func (r *StoredResult) Metadata() Check {
+	return r.Check
+}
+
+// NewFailedResult returns the result of a check run in the given account and
+// region that failed to run with err.
+func NewFailedResult(check Check, accountId string, region string, params Parameters, err error) *StoredResult {
+	res := &StoredResult{Check: check, CheckResult: NewCheckResult(accountId, region, params)}
+	res.Status = StatusFailedToRun
+	res.Error = err.Error()
+	return res
+}
+
+// ParseResults parses the json output of a check run, which lists the results
+// of each category under the id of the category.
+func ParseResults(content []byte) ([]Result, error) {
+	var categories map[string]json.RawMessage
+	if err := json.Unmarshal(content, &categories); err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	var found bool
+	for _, category := range []string{
+		CategoryCostOptimization,
+		CategoryPerformance,
+		CategorySecurity,
+		CategoryFaultTolerance,
+		CategoryServiceLimits,
+	} {
+		raw, ok := categories[category]
+		if !ok {
+			continue
+		}
+		found = true
+		var stored []*StoredResult
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			return nil, fmt.Errorf("invalid %s results: %w", category, err)
+		}
+		for _, res := range stored {
+			results = append(results, res)
+		}
+	}
+	if !found {
+		return nil, errors.New("no check result categories found")
+	}
+	return results, nil
+}
+
+// LoadResults reads the json output of a check run written to path with the
+// out-file flag.
+func LoadResults(path string) ([]Result, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	results, err := ParseResults(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse results file (%s), expected the json output of a check run: %w", path, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseResults(t *testing.T) {
+	result := StoredResult{
+		Check:       Check{Id: "ckia:aws:cost:UnassociatedElasticIPAddresses", Category: CategoryCostOptimization, Severity: SeverityLow},
+		CheckResult: NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	result.ResourcesEvaluated = 2
+	result.AddFinding(Finding{ResourceId: "eipalloc-01", AccountId: "123456789011", EstimatedMonthlySavings: 3.6})
+	result.Evaluate(result.Severity)
+
+	content, err := json.Marshal(map[string]interface{}{
+		"estimatedMonthlySavings": 3.6,
+		CategoryCostOptimization:  []interface{}{result},
+		CategorySecurity:          []interface{}{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ParseResults(content)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing results: %s", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Results should contain 1 result, Got %d", len(results))
+	}
+	if results[0].Metadata().Id != result.Id {
+		t.Fatalf("Check id should be %s, Got %s", result.Id, results[0].Metadata().Id)
+	}
+	summary := results[0].Summary()
+	if summary.Status != StatusWarning || len(summary.Findings) != 1 || summary.Findings[0].ResourceId != "eipalloc-01" {
+		t.Fatalf("Unexpected parsed result: %+v", summary)
+	}
+}
+
+func TestParseResults_invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"not json":      "# ckia report",
+		"no categories": `{"version": "2.1.0", "runs": []}`,
+		"bad category":  `{"security": {"id": "ckia:aws:security:RootAccountMissingMFA"}}`,
+	} {
+		if _, err := ParseResults([]byte(content)); err == nil {
+			t.Fatalf("Expected an error parsing results with %s.", name)
+		}
+	}
+}
+
+func TestNewFailedResult(t *testing.T) {
+	check := Check{Id: "ckia:aws:cost:IdleDBInstances", Severity: SeverityMedium}
+	result := NewFailedResult(check, "123456789011", "us-east-1", Parameters{"lookbackDays": 14}, errors.New("AccessDenied"))
+
+	if result.Metadata().Id != check.Id || result.AccountId != "123456789011" || result.Region != "us-east-1" {
+		t.Fatalf("Unexpected failed result: %+v", result)
+	}
+	if result.Status != StatusFailedToRun || result.Error != "AccessDenied" {
+		t.Fatalf("Status should be %s with the error, Got %s (%s)", StatusFailedToRun, result.Status, result.Error)
+	}
+
+	result.Suppress(check.Severity, func(finding Finding) (SuppressedFinding, bool) {
+		return SuppressedFinding{Finding: finding}, true
+	})
+	if result.Status != StatusFailedToRun {
+		t.Fatalf("Suppressing a failed result should not change its status, Got %s", result.Status)
+	}
+}
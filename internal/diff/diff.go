@@ -0,0 +1,161 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+// Finding is a flagged resource along with the check that flagged it.
+type Finding struct {
+	CheckId   string `json:"checkId"`
+	CheckName string `json:"checkName"`
+	Category  string `json:"category"`
+	Severity  string `json:"severity"`
+	common.Finding
+}
+
+// Diff is the comparison of the findings of two check runs. Findings are
+// matched by check id, account, region and resource id.
+type Diff struct {
+	// New are findings of the new run that were not flagged in the old run.
+	New []Finding `json:"new"`
+	// Resolved are findings of the old run that are no longer flagged.
+	Resolved []Finding `json:"resolved"`
+	// Unchanged are findings flagged in both runs, as of the new run.
+	Unchanged []Finding `json:"unchanged"`
+	// NotCompared are findings of the old run of a check that failed to run in
+	// the new run, so whether they are still flagged is unknown.
+	NotCompared []Finding `json:"notCompared"`
+
+	// OldEstimatedMonthlySavings excludes the savings of findings that were not
+	// compared.
+	OldEstimatedMonthlySavings float64 `json:"oldEstimatedMonthlySavings"`
+	NewEstimatedMonthlySavings float64 `json:"newEstimatedMonthlySavings"`
+	// EstimatedMonthlySavingsChange is the new savings minus the old savings.
+	EstimatedMonthlySavingsChange float64 `json:"estimatedMonthlySavingsChange"`
+}
+
+// run identifies the run of a check in an account and region.
+type run struct {
+	checkId   string
+	accountId string
+	region    string
+}
+
+type key struct {
+	checkId    string
+	accountId  string
+	region     string
+	resourceId string
+}
+
+// Compare compares the flagged findings of the old and new results. Suppressed
+// findings are not compared, and neither are the old findings of a check that
+// failed to run in the new results.
+func Compare(oldResults []common.Result, newResults []common.Result) Diff {
+	d := Diff{New: []Finding{}, Resolved: []Finding{}, Unchanged: []Finding{}, NotCompared: []Finding{}}
+
+	failed := failedRuns(newResults)
+	var compared []common.Result
+	for _, res := range oldResults {
+		if failed[runOf(res)] {
+			for _, finding := range findingsByKey([]common.Result{res}) {
+				d.NotCompared = append(d.NotCompared, finding)
+			}
+			continue
+		}
+		compared = append(compared, res)
+	}
+	oldResults = compared
+
+	oldFindings := findingsByKey(oldResults)
+	newFindings := findingsByKey(newResults)
+	for k, finding := range newFindings {
+		if _, ok := oldFindings[k]; ok {
+			d.Unchanged = append(d.Unchanged, finding)
+			continue
+		}
+		d.New = append(d.New, finding)
+	}
+	for k, finding := range oldFindings {
+		if _, ok := newFindings[k]; !ok {
+			d.Resolved = append(d.Resolved, finding)
+		}
+	}
+	sortFindings(d.New)
+	sortFindings(d.Resolved)
+	sortFindings(d.Unchanged)
+	sortFindings(d.NotCompared)
+
+	d.OldEstimatedMonthlySavings = totalSavings(oldResults)
+	d.NewEstimatedMonthlySavings = totalSavings(newResults)
+	d.EstimatedMonthlySavingsChange = common.RoundCents(d.NewEstimatedMonthlySavings - d.OldEstimatedMonthlySavings)
+	return d
+}
+
+func runOf(res common.Result) run {
+	summary := res.Summary()
+	return run{res.Metadata().Id, summary.AccountId, summary.Region}
+}
+
+// failedRuns returns the check runs of results that failed to run.
+func failedRuns(results []common.Result) map[run]bool {
+	failed := map[run]bool{}
+	for _, res := range results {
+		if res.Summary().Status == common.StatusFailedToRun {
+			failed[runOf(res)] = true
+		}
+	}
+	return failed
+}
+
+func findingsByKey(results []common.Result) map[key]Finding {
+	findings := map[key]Finding{}
+	for _, res := range results {
+		check := res.Metadata()
+		for _, finding := range res.Summary().Findings {
+			findings[key{check.Id, finding.AccountId, finding.Region, finding.ResourceId}] = Finding{
+				CheckId:   check.Id,
+				CheckName: check.Name,
+				Category:  check.Category,
+				Severity:  check.Severity,
+				Finding:   finding,
+			}
+		}
+	}
+	return findings
+}
+
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.CheckId != b.CheckId {
+			return a.CheckId < b.CheckId
+		}
+		if a.AccountId != b.AccountId {
+			return a.AccountId < b.AccountId
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.ResourceId < b.ResourceId
+	})
+}
+
+func totalSavings(results []common.Result) float64 {
+	var savings float64
+	for _, res := range results {
+		savings = common.RoundCents(savings + res.Summary().EstimatedMonthlySavings)
+	}
+	return savings
+}
+
+// Savings returns the total estimated monthly savings of findings.
+func Savings(findings []Finding) float64 {
+	var savings float64
+	for _, finding := range findings {
+		savings = common.RoundCents(savings + finding.EstimatedMonthlySavings)
+	}
+	return savings
+}
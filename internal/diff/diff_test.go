@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+var eipCheck = common.Check{
+	Id:       "ckia:aws:cost:UnassociatedElasticIPAddresses",
+	Category: common.CategoryCostOptimization,
+	Severity: common.SeverityLow,
+	Name:     "Unassociated Elastic IP Addresses",
+}
+
+func newTestResult(region string, findings ...common.Finding) *common.StoredResult {
+	res := &common.StoredResult{
+		Check:       eipCheck,
+		CheckResult: common.NewCheckResult("123456789011", region, nil),
+	}
+	res.ResourcesEvaluated = len(findings)
+	for _, finding := range findings {
+		res.AddFinding(finding)
+	}
+	res.Evaluate(res.Severity)
+	return res
+}
+
+func eip(region string, id string) common.Finding {
+	return common.Finding{
+		ResourceId:              id,
+		AccountId:               "123456789011",
+		Region:                  region,
+		Reason:                  "not associated",
+		EstimatedMonthlySavings: 3.6,
+	}
+}
+
+func newTestDiff() Diff {
+	oldResults := []common.Result{
+		newTestResult("us-east-1", eip("us-east-1", "eipalloc-01"), eip("us-east-1", "eipalloc-02")),
+		newTestResult("us-west-2", eip("us-west-2", "eipalloc-01")),
+	}
+	newResults := []common.Result{
+		newTestResult("us-east-1", eip("us-east-1", "eipalloc-02"), eip("us-east-1", "eipalloc-03"), eip("us-east-1", "eipalloc-04")),
+		newTestResult("us-west-2"),
+	}
+	return Compare(oldResults, newResults)
+}
+
+func TestCompare(t *testing.T) {
+	d := newTestDiff()
+
+	resourceIds := func(findings []Finding) []string {
+		var ids []string
+		for _, finding := range findings {
+			ids = append(ids, finding.Region+"/"+finding.ResourceId)
+		}
+		return ids
+	}
+	for name, c := range map[string]struct {
+		findings []Finding
+		want     []string
+	}{
+		"new":       {d.New, []string{"us-east-1/eipalloc-03", "us-east-1/eipalloc-04"}},
+		"resolved":  {d.Resolved, []string{"us-east-1/eipalloc-01", "us-west-2/eipalloc-01"}},
+		"unchanged": {d.Unchanged, []string{"us-east-1/eipalloc-02"}},
+	} {
+		got := resourceIds(c.findings)
+		if len(got) != len(c.want) {
+			t.Fatalf("%s findings should be %v, Got %v", name, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("%s findings should be %v, Got %v", name, c.want, got)
+			}
+		}
+	}
+
+	if d.New[0].CheckId != eipCheck.Id || d.New[0].Category != common.CategoryCostOptimization {
+		t.Fatalf("Findings should include the check that flagged them, Got %+v", d.New[0])
+	}
+	if d.OldEstimatedMonthlySavings != 10.8 || d.NewEstimatedMonthlySavings != 10.8 || d.EstimatedMonthlySavingsChange != 0 {
+		t.Fatalf("Unexpected savings, Got %.2f -> %.2f (%.2f)", d.OldEstimatedMonthlySavings, d.NewEstimatedMonthlySavings, d.EstimatedMonthlySavingsChange)
+	}
+}
+
+func TestCompare_suppressed(t *testing.T) {
+	old := []common.Result{newTestResult("us-east-1", eip("us-east-1", "eipalloc-01"))}
+	res := newTestResult("us-east-1", eip("us-east-1", "eipalloc-01"))
+	res.Suppress(res.Severity, func(finding common.Finding) (common.SuppressedFinding, bool) {
+		return common.SuppressedFinding{Finding: finding, SuppressedBy: common.SuppressedBySuppression}, true
+	})
+
+	d := Compare(old, []common.Result{res})
+	if len(d.Resolved) != 1 || len(d.Unchanged) != 0 {
+		t.Fatalf("A finding suppressed in the new run should be resolved, Got %+v", d)
+	}
+	if d.EstimatedMonthlySavingsChange != -3.6 {
+		t.Fatalf("Savings change should be -3.60, Got %.2f", d.EstimatedMonthlySavingsChange)
+	}
+}
+
+func TestCompare_failedToRun(t *testing.T) {
+	old := []common.Result{
+		newTestResult("us-east-1", eip("us-east-1", "eipalloc-01")),
+		newTestResult("us-west-2", eip("us-west-2", "eipalloc-01")),
+	}
+	failed := common.NewFailedResult(eipCheck, "123456789011", "us-east-1", nil, errors.New("AccessDenied"))
+
+	d := Compare(old, []common.Result{failed, newTestResult("us-west-2")})
+	if len(d.NotCompared) != 1 || d.NotCompared[0].Region != "us-east-1" {
+		t.Fatalf("Findings of a check that failed to run should not be compared, Got %+v", d.NotCompared)
+	}
+	if len(d.Resolved) != 1 || d.Resolved[0].Region != "us-west-2" {
+		t.Fatalf("Only findings of a check that ran should be resolved, Got %+v", d.Resolved)
+	}
+	if d.OldEstimatedMonthlySavings != 3.6 || d.EstimatedMonthlySavingsChange != -3.6 {
+		t.Fatalf("Savings of findings that were not compared should be excluded, Got %.2f (%.2f)", d.OldEstimatedMonthlySavings, d.EstimatedMonthlySavingsChange)
+	}
+}
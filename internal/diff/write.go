@@ -0,0 +1,172 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	FormatTable    = "table"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// Writer renders a diff in an output format.
+type Writer func(w io.Writer, d Diff) error
+
+var writers = map[string]Writer{
+	FormatTable:    WriteTable,
+	FormatMarkdown: WriteMarkdown,
+	FormatJSON:     WriteJSON,
+}
+
+// section is a list of findings of a diff in the order and with the title it
+// is reported under.
+type section struct {
+	Title    string
+	Findings []Finding
+}
+
+func (d Diff) sections() []section {
+	return []section{
+		{Title: "New", Findings: d.New},
+		{Title: "Resolved", Findings: d.Resolved},
+		{Title: "Unchanged", Findings: d.Unchanged},
+		{Title: "Not compared", Findings: d.NotCompared},
+	}
+}
+
+// Formats returns the names of the output formats supported by Write.
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for format := range writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Write renders d to w in format.
+func Write(format string, w io.Writer, d Diff) error {
+	writer, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	return writer(w, d)
+}
+
+// WriteJSON renders d as indented json.
+func WriteJSON(w io.Writer, d Diff) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(d)
+}
+
+// WriteTable renders d for terminals, with a summary of the number of findings
+// and savings of each section followed by a table of the findings of each
+// section.
+func WriteTable(w io.Writer, d Diff) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FINDINGS\tCOUNT\tSAVINGS")
+	for _, s := range d.sections() {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", s.Title, len(s.Findings), formatSavings(Savings(s.Findings)))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, s := range d.sections() {
+		if len(s.Findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n\n", s.Title)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  CHECK\tRESOURCE\tACCOUNT\tREGION\tSAVINGS\tREASON")
+		for _, finding := range s.Findings {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\n",
+				finding.shortName(),
+				finding.ResourceId,
+				finding.AccountId,
+				formatRegion(finding.Region),
+				formatSavings(finding.EstimatedMonthlySavings),
+				finding.Reason,
+			)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nEstimated monthly savings: $%.2f -> $%.2f (%s)\n",
+		d.OldEstimatedMonthlySavings, d.NewEstimatedMonthlySavings, formatChange(d.EstimatedMonthlySavingsChange))
+	return err
+}
+
+// WriteMarkdown renders d as a markdown report suitable for a ticket or wiki
+// page.
+func WriteMarkdown(w io.Writer, d Diff) error {
+	fmt.Fprintf(w, "# ckia diff\n\n")
+	fmt.Fprintf(w, "**Estimated monthly savings:** $%.2f -> $%.2f (%s)\n\n",
+		d.OldEstimatedMonthlySavings, d.NewEstimatedMonthlySavings, formatChange(d.EstimatedMonthlySavingsChange))
+	fmt.Fprintln(w, "| Findings | Count | Savings |")
+	fmt.Fprintln(w, "|---|---:|---:|")
+	for _, s := range d.sections() {
+		fmt.Fprintf(w, "| %s | %d | %s |\n", s.Title, len(s.Findings), formatSavings(Savings(s.Findings)))
+	}
+
+	for _, s := range d.sections() {
+		if len(s.Findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n## %s\n\n", s.Title)
+		fmt.Fprintln(w, "| Check | Resource | Account | Region | Reason | Savings |")
+		fmt.Fprintln(w, "|---|---|---|---|---|---:|")
+		for _, finding := range s.Findings {
+			fmt.Fprintf(w, "| %s | `%s` | %s | %s | %s | %s |\n",
+				finding.shortName(),
+				finding.ResourceId,
+				finding.AccountId,
+				formatRegion(finding.Region),
+				markdownCell(finding.Reason),
+				formatSavings(finding.EstimatedMonthlySavings),
+			)
+		}
+	}
+	return nil
+}
+
+func (f Finding) shortName() string {
+	return common.Check{Id: f.CheckId}.ShortName()
+}
+
+func formatSavings(savings float64) string {
+	if savings == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", savings)
+}
+
+func formatChange(change float64) string {
+	if change < 0 {
+		return fmt.Sprintf("-$%.2f", -change)
+	}
+	return fmt.Sprintf("+$%.2f", change)
+}
+
+func formatRegion(region string) string {
+	if region == "" {
+		return "global"
+	}
+	return region
+}
+
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatTable, &buf, newTestDiff()); err != nil {
+		t.Fatalf("Unexpected error writing table: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"New           2      $7.20",
+		"Resolved      2      $7.20",
+		"Unchanged     1      $3.60",
+		"Not compared  0      -",
+		"UnassociatedElasticIPAddresses  eipalloc-03  123456789011  us-east-1  $3.60    not associated",
+		"Estimated monthly savings: $10.80 -> $10.80 (+$0.00)",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Table should contain %q, Got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatMarkdown, &buf, newTestDiff()); err != nil {
+		t.Fatalf("Unexpected error writing markdown: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"**Estimated monthly savings:** $10.80 -> $10.80 (+$0.00)",
+		"| New | 2 | $7.20 |",
+		"## Resolved",
+		"| UnassociatedElasticIPAddresses | `eipalloc-01` | 123456789011 | us-west-2 | not associated | $3.60 |",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Markdown should contain %q, Got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatJSON, &buf, newTestDiff()); err != nil {
+		t.Fatalf("Unexpected error writing json: %s", err)
+	}
+
+	var d Diff
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		t.Fatalf("Output should be valid json: %s", err)
+	}
+	if len(d.New) != 2 || d.New[0].ResourceId != "eipalloc-03" || d.New[0].CheckId != eipCheck.Id {
+		t.Fatalf("Unexpected new findings, Got %+v", d.New)
+	}
+}
+
+func TestFormatChange(t *testing.T) {
+	if formatChange(-3.6) != "-$3.60" || formatChange(1.25) != "+$1.25" {
+		t.Fatalf("Unexpected savings change, Got %s and %s", formatChange(-3.6), formatChange(1.25))
+	}
+}
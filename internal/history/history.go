@@ -0,0 +1,241 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+// runIdFormat is the layout of run ids, which sort in the order runs were
+// recorded.
+const runIdFormat = "20060102T150405Z"
+
+// Store is a directory of check runs, one json file per run named after the
+// id of the run.
+type Store struct {
+	Dir string
+}
+
+// Run is the json output of a check run recorded in a store.
+type Run struct {
+	Id        string          `json:"id"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Results   json.RawMessage `json:"results"`
+}
+
+// DefaultDir returns the location of the history store in the user config
+// directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ckia", "history"), nil
+}
+
+// NewStore returns the store in dir, or in the default directory when dir is
+// empty.
+func NewStore(dir string) (Store, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return Store{}, err
+		}
+	}
+	return Store{Dir: dir}, nil
+}
+
+// Save records results, the json output of a check run, as a new run created
+// at createdAt. Runs created within the same second get a numbered suffix.
+func (s Store) Save(createdAt time.Time, results []byte) (*Run, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	run := &Run{CreatedAt: createdAt.UTC(), Results: results}
+	base := run.CreatedAt.Format(runIdFormat)
+	for i := 1; ; i++ {
+		run.Id = base
+		if i > 1 {
+			run.Id = fmt.Sprintf("%s-%d", base, i)
+		}
+		f, err := os.OpenFile(s.path(run.Id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return run, json.NewEncoder(f).Encode(run)
+	}
+}
+
+// Latest is the id that Load resolves to the newest run in the store.
+const Latest = "latest"
+
+// Load reads the run with id, or the newest run when id is Latest.
+func (s Store) Load(id string) (*Run, error) {
+	if id == Latest {
+		ids, err := s.ids()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no runs recorded in %s", s.Dir)
+		}
+		id = ids[len(ids)-1]
+	}
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid run id: %s", id)
+	}
+	content, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("run (%s) not found in %s", id, s.Dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var run Run
+	if err := json.Unmarshal(content, &run); err != nil {
+		return nil, fmt.Errorf("unable to parse run (%s): %w", id, err)
+	}
+	return &run, nil
+}
+
+// Runs returns every run in the store from oldest to newest. A store that does
+// not exist yet has no runs.
+func (s Store) Runs() ([]*Run, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*Run, 0, len(ids))
+	for _, id := range ids {
+		run, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// ids returns the ids of every run in the store from oldest to newest.
+func (s Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s Store) path(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
+
+// CheckResults parses the results of the run.
+func (r *Run) CheckResults() ([]common.Result, error) {
+	results, err := common.ParseResults(r.Results)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse results of run (%s): %w", r.Id, err)
+	}
+	return results, nil
+}
+
+// Filter selects the check results of a run. Empty fields match every result.
+type Filter struct {
+	// CheckId is the full check id or the last segment of it.
+	CheckId   string
+	AccountId string
+	Region    string
+}
+
+// Matches reports whether res is selected by the filter.
+func (f Filter) Matches(res common.Result) bool {
+	check := res.Metadata()
+	summary := res.Summary()
+	return (f.CheckId == "" || strings.EqualFold(f.CheckId, check.Id) || strings.EqualFold(f.CheckId, check.ShortName())) &&
+		(f.AccountId == "" || f.AccountId == summary.AccountId) &&
+		(f.Region == "" || f.Region == summary.Region)
+}
+
+// Summary is the totals of the check results of a run selected by a filter.
+type Summary struct {
+	RunId                   string    `json:"runId"`
+	CreatedAt               time.Time `json:"createdAt"`
+	Accounts                []string  `json:"accounts"`
+	Regions                 []string  `json:"regions"`
+	CheckRuns               int       `json:"checkRuns"`
+	ResourcesFlagged        int       `json:"resourcesFlagged"`
+	Suppressed              int       `json:"suppressed"`
+	EstimatedMonthlySavings float64   `json:"estimatedMonthlySavings"`
+}
+
+// Summarize totals the check results of run selected by filter.
+func Summarize(run *Run, filter Filter) (Summary, error) {
+	summary := Summary{RunId: run.Id, CreatedAt: run.CreatedAt, Accounts: []string{}, Regions: []string{}}
+	results, err := run.CheckResults()
+	if err != nil {
+		return summary, err
+	}
+
+	for _, res := range results {
+		if !filter.Matches(res) {
+			continue
+		}
+		result := res.Summary()
+		if !common.StringSliceContains(summary.Accounts, result.AccountId) {
+			summary.Accounts = append(summary.Accounts, result.AccountId)
+		}
+		if result.Region != "" && !common.StringSliceContains(summary.Regions, result.Region) {
+			summary.Regions = append(summary.Regions, result.Region)
+		}
+		summary.CheckRuns++
+		summary.ResourcesFlagged += result.ResourcesFlagged
+		summary.Suppressed += len(result.Suppressed)
+		summary.EstimatedMonthlySavings = common.RoundCents(summary.EstimatedMonthlySavings + result.EstimatedMonthlySavings)
+	}
+	sort.Strings(summary.Accounts)
+	sort.Strings(summary.Regions)
+	return summary, nil
+}
+
+// Trend summarizes the check results selected by filter in every run, from
+// oldest to newest. Runs without any selected check results are skipped.
+func Trend(runs []*Run, filter Filter) ([]Summary, error) {
+	trend := []Summary{}
+	for _, run := range runs {
+		summary, err := Summarize(run, filter)
+		if err != nil {
+			return nil, err
+		}
+		if summary.CheckRuns == 0 {
+			continue
+		}
+		trend = append(trend, summary)
+	}
+	return trend, nil
+}
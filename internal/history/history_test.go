@@ -0,0 +1,142 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+var eipCheck = common.Check{
+	Id:       "ckia:aws:cost:UnassociatedElasticIPAddresses",
+	Category: common.CategoryCostOptimization,
+	Severity: common.SeverityLow,
+}
+
+// testRun returns the json output of a check run with a result per region,
+// flagging the number of elastic ips of the region.
+func testRun(t *testing.T, flagged map[string]int) []byte {
+	var results []interface{}
+	for region, count := range flagged {
+		res := common.StoredResult{Check: eipCheck, CheckResult: common.NewCheckResult("123456789011", region, nil)}
+		res.ResourcesEvaluated = count
+		for i := 0; i < count; i++ {
+			res.AddFinding(common.Finding{ResourceId: "eipalloc-01", AccountId: "123456789011", Region: region, EstimatedMonthlySavings: 3.6})
+		}
+		res.Evaluate(res.Severity)
+		results = append(results, res)
+	}
+
+	content, err := json.Marshal(map[string]interface{}{
+		common.CategoryCostOptimization: results,
+		common.CategorySecurity:         []interface{}{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+
+	runs, err := store.Runs()
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("An empty store should have no runs, Got %d (%v)", len(runs), err)
+	}
+
+	createdAt := time.Date(2023, 6, 12, 10, 15, 0, 0, time.UTC)
+	first, err := store.Save(createdAt, testRun(t, map[string]int{"us-east-1": 1}))
+	if err != nil {
+		t.Fatalf("Unexpected error saving run: %s", err)
+	}
+	second, err := store.Save(createdAt, testRun(t, map[string]int{"us-east-1": 2}))
+	if err != nil {
+		t.Fatalf("Unexpected error saving run: %s", err)
+	}
+	if first.Id != "20230612T101500Z" || second.Id != "20230612T101500Z-2" {
+		t.Fatalf("Unexpected run ids, Got %s and %s", first.Id, second.Id)
+	}
+
+	runs, err = store.Runs()
+	if err != nil {
+		t.Fatalf("Unexpected error listing runs: %s", err)
+	}
+	if len(runs) != 2 || runs[0].Id != first.Id || runs[1].Id != second.Id {
+		t.Fatalf("Runs should be listed from oldest to newest, Got %d runs", len(runs))
+	}
+
+	latest, err := store.Load(Latest)
+	if err != nil {
+		t.Fatalf("Unexpected error loading latest run: %s", err)
+	}
+	if latest.Id != second.Id || !latest.CreatedAt.Equal(createdAt) {
+		t.Fatalf("Latest run should be %s, Got %s", second.Id, latest.Id)
+	}
+	results, err := latest.CheckResults()
+	if err != nil {
+		t.Fatalf("Unexpected error parsing results: %s", err)
+	}
+	if len(results) != 1 || results[0].Summary().ResourcesFlagged != 2 {
+		t.Fatalf("Unexpected results of latest run, Got %d results", len(results))
+	}
+}
+
+func TestStoreLoad_invalid(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	for _, id := range []string{Latest, "20230612T101500Z", "../config", ""} {
+		if _, err := store.Load(id); err == nil {
+			t.Fatalf("Expected an error loading run (%s).", id)
+		}
+	}
+}
+
+func TestTrend(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	start := time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC)
+	for i, flagged := range []map[string]int{
+		{"us-east-1": 3, "us-west-2": 1},
+		{"us-east-1": 2},
+		{"us-east-1": 0, "us-west-2": 2},
+	} {
+		if _, err := store.Save(start.AddDate(0, 0, 7*i), testRun(t, flagged)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := store.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trend, err := Trend(runs, Filter{CheckId: "UnassociatedElasticIPAddresses"})
+	if err != nil {
+		t.Fatalf("Unexpected error building trend: %s", err)
+	}
+	var flagged []int
+	for _, summary := range trend {
+		flagged = append(flagged, summary.ResourcesFlagged)
+	}
+	if len(flagged) != 3 || flagged[0] != 4 || flagged[1] != 2 || flagged[2] != 2 {
+		t.Fatalf("Flagged resources should be [4 2 2], Got %v", flagged)
+	}
+	if trend[0].EstimatedMonthlySavings != 14.4 || len(trend[0].Regions) != 2 || trend[0].CheckRuns != 2 {
+		t.Fatalf("Unexpected summary of first run, Got %+v", trend[0])
+	}
+
+	trend, err = Trend(runs, Filter{CheckId: eipCheck.Id, Region: "us-west-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trend) != 2 || trend[0].ResourcesFlagged != 1 || trend[1].ResourcesFlagged != 2 {
+		t.Fatalf("Runs without results in us-west-2 should be skipped, Got %+v", trend)
+	}
+
+	trend, err = Trend(runs, Filter{CheckId: "RootAccountMissingMFA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trend) != 0 {
+		t.Fatalf("A check without results should have an empty trend, Got %d runs", len(trend))
+	}
+}
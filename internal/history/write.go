@@ -0,0 +1,65 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// Formats returns the names of the output formats supported by WriteSummaries.
+func Formats() []string {
+	return []string{FormatJSON, FormatTable}
+}
+
+// WriteSummaries renders summaries of runs to w in format, one row per run.
+func WriteSummaries(format string, w io.Writer, summaries []Summary) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		return encoder.Encode(summaries)
+	case FormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RUN\tCREATED\tACCOUNTS\tREGIONS\tCHECK RUNS\tFLAGGED\tSUPPRESSED\tSAVINGS")
+		for _, summary := range summaries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+				summary.RunId,
+				summary.CreatedAt.Format(time.RFC3339),
+				formatList(summary.Accounts),
+				formatList(summary.Regions),
+				summary.CheckRuns,
+				summary.ResourcesFlagged,
+				summary.Suppressed,
+				formatSavings(summary.EstimatedMonthlySavings),
+			)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format: %s", format)
+}
+
+// formatList joins values, abbreviating long lists to their count.
+func formatList(values []string) string {
+	switch {
+	case len(values) == 0:
+		return "-"
+	case len(values) > 3:
+		return fmt.Sprintf("%d", len(values))
+	}
+	return strings.Join(values, ",")
+}
+
+func formatSavings(savings float64) string {
+	if savings == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", savings)
+}
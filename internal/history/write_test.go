@@ -0,0 +1,70 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSummaries() []Summary {
+	return []Summary{
+		{
+			RunId:                   "20230605T100000Z",
+			CreatedAt:               time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC),
+			Accounts:                []string{"123456789011"},
+			Regions:                 []string{"us-east-1", "us-west-2"},
+			CheckRuns:               2,
+			ResourcesFlagged:        4,
+			Suppressed:              1,
+			EstimatedMonthlySavings: 14.4,
+		},
+		{
+			RunId:     "20230612T100000Z",
+			CreatedAt: time.Date(2023, 6, 12, 10, 0, 0, 0, time.UTC),
+			Accounts:  []string{"123456789011"},
+			Regions:   []string{"af-south-1", "ap-east-1", "us-east-1", "us-west-2"},
+			CheckRuns: 4,
+		},
+	}
+}
+
+func TestWriteSummaries_table(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSummaries(FormatTable, &buf, newTestSummaries()); err != nil {
+		t.Fatalf("Unexpected error writing table: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"RUN               CREATED               ACCOUNTS      REGIONS              CHECK RUNS  FLAGGED  SUPPRESSED  SAVINGS",
+		"20230605T100000Z  2023-06-05T10:00:00Z  123456789011  us-east-1,us-west-2  2           4        1           $14.40",
+		"20230612T100000Z  2023-06-12T10:00:00Z  123456789011  4                    4           0        0           -",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Table should contain %q, Got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWriteSummaries_json(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSummaries(FormatJSON, &buf, newTestSummaries()); err != nil {
+		t.Fatalf("Unexpected error writing json: %s", err)
+	}
+
+	var summaries []Summary
+	if err := json.Unmarshal(buf.Bytes(), &summaries); err != nil {
+		t.Fatalf("Output should be valid json: %s", err)
+	}
+	if len(summaries) != 2 || summaries[0].ResourcesFlagged != 4 {
+		t.Fatalf("Unexpected summaries, Got %+v", summaries)
+	}
+}
+
+func TestWriteSummaries_unsupported(t *testing.T) {
+	if err := WriteSummaries("xml", &bytes.Buffer{}, nil); err == nil {
+		t.Fatal("Expected an error for an unsupported format.")
+	}
+}
@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	// Any fails on every flagged finding.
+	Any = "any"
+	// None never fails on findings, which is the default.
+	None = "none"
+)
+
+// severityRank orders severities from least to most severe.
+var severityRank = map[string]int{
+	common.SeverityLow:      1,
+	common.SeverityMedium:   2,
+	common.SeverityHigh:     3,
+	common.SeverityCritical: 4,
+}
+
+var categories = []string{
+	common.CategoryCostOptimization,
+	common.CategoryPerformance,
+	common.CategorySecurity,
+	common.CategoryFaultTolerance,
+	common.CategoryServiceLimits,
+}
+
+// operators are the comparison operators of a condition, longest first so
+// severity>=high is not read as severity>"=high".
+var operators = []string{">=", "<=", "!=", "=", ">", "<"}
+
+// Policy decides which flagged findings fail a run. A finding fails the run
+// when it matches any of the conditions of the policy.
+type Policy struct {
+	conditions []condition
+}
+
+type condition struct {
+	field    string
+	operator string
+	value    string
+}
+
+// Parse parses fail-on expressions into a policy. Every expression is a comma
+// separated list of conditions: any, none, severity<op><severity>,
+// category=<category> or check=<check id>, where op is one of =, !=, >, >=, <
+// or <=. Only severity supports ordering operators.
+func Parse(expressions []string) (Policy, error) {
+	var p Policy
+	for _, expression := range expressions {
+		for _, term := range strings.Split(expression, ",") {
+			term = strings.TrimSpace(term)
+			switch strings.ToLower(term) {
+			case "", None:
+				continue
+			case Any:
+				p.conditions = append(p.conditions, condition{field: Any})
+				continue
+			}
+
+			c, err := parseCondition(term)
+			if err != nil {
+				return Policy{}, err
+			}
+			p.conditions = append(p.conditions, c)
+		}
+	}
+	return p, nil
+}
+
+func parseCondition(term string) (condition, error) {
+	for _, operator := range operators {
+		i := strings.Index(term, operator)
+		if i < 0 {
+			continue
+		}
+		c := condition{
+			field:    strings.ToLower(strings.TrimSpace(term[:i])),
+			operator: operator,
+			value:    strings.TrimSpace(term[i+len(operator):]),
+		}
+		return c, c.validate(term)
+	}
+	return condition{}, fmt.Errorf("invalid fail-on condition (%s), expected any, none or <field><operator><value>", term)
+}
+
+func (c *condition) validate(term string) error {
+	ordered := c.operator != "=" && c.operator != "!="
+	switch c.field {
+	case "severity":
+		c.value = strings.ToLower(c.value)
+		if _, ok := severityRank[c.value]; !ok {
+			return fmt.Errorf("invalid severity in fail-on condition (%s), expected one of: low, medium, high, critical", term)
+		}
+		return nil
+	case "category":
+		if ordered {
+			return fmt.Errorf("invalid operator in fail-on condition (%s), category only supports = and !=", term)
+		}
+		for _, category := range categories {
+			if strings.EqualFold(category, c.value) {
+				c.value = category
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid category in fail-on condition (%s), expected one of: %s", term, strings.Join(categories, ", "))
+	case "check":
+		if ordered {
+			return fmt.Errorf("invalid operator in fail-on condition (%s), check only supports = and !=", term)
+		}
+		if c.value == "" {
+			return fmt.Errorf("missing check id in fail-on condition (%s)", term)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid field in fail-on condition (%s), expected severity, category or check", term)
+}
+
+func (c condition) matches(check common.Check) bool {
+	var match bool
+	switch c.field {
+	case Any:
+		return true
+	case "severity":
+		rank, want := severityRank[strings.ToLower(check.Severity)], severityRank[c.value]
+		switch c.operator {
+		case ">=":
+			return rank >= want
+		case ">":
+			return rank > want
+		case "<=":
+			return rank <= want
+		case "<":
+			return rank < want
+		}
+		match = rank == want
+	case "category":
+		match = check.Category == c.value
+	case "check":
+		match = strings.EqualFold(check.Id, c.value) || strings.EqualFold(check.ShortName(), c.value)
+	}
+	if c.operator == "!=" {
+		return !match
+	}
+	return match
+}
+
+// IsEmpty reports whether the policy never fails on findings.
+func (p Policy) IsEmpty() bool {
+	return len(p.conditions) == 0
+}
+
+// Matches reports whether findings of check fail the run.
+func (p Policy) Matches(check common.Check) bool {
+	for _, c := range p.conditions {
+		if c.matches(check) {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures returns the number of flagged findings in results that fail the
+// run. Suppressed findings never fail the run.
+func (p Policy) Failures(results []common.Result) int {
+	var failures int
+	for _, res := range results {
+		if p.Matches(res.Metadata()) {
+			failures += len(res.Summary().Findings)
+		}
+	}
+	return failures
+}
@@ -0,0 +1,106 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func newTestResult(check common.Check, findings ...common.Finding) *common.StoredResult {
+	res := &common.StoredResult{
+		Check:       check,
+		CheckResult: common.NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	res.ResourcesEvaluated = len(findings)
+	for _, finding := range findings {
+		res.AddFinding(finding)
+	}
+	res.Evaluate(res.Severity)
+	return res
+}
+
+var (
+	mfaCheck = common.Check{Id: "ckia:aws:security:RootAccountMissingMFA", Category: common.CategorySecurity, Severity: common.SeverityCritical}
+	eipCheck = common.Check{Id: "ckia:aws:cost:UnassociatedElasticIPAddresses", Category: common.CategoryCostOptimization, Severity: common.SeverityLow}
+	rdsCheck = common.Check{Id: "ckia:aws:faulttolerance:RDSSingleAZInstances", Category: common.CategoryFaultTolerance, Severity: common.SeverityMedium}
+)
+
+func TestPolicy_Matches(t *testing.T) {
+	cases := []struct {
+		expressions []string
+		want        map[string]bool
+	}{
+		{nil, map[string]bool{mfaCheck.Id: false, eipCheck.Id: false, rdsCheck.Id: false}},
+		{[]string{"none"}, map[string]bool{mfaCheck.Id: false, eipCheck.Id: false, rdsCheck.Id: false}},
+		{[]string{"any"}, map[string]bool{mfaCheck.Id: true, eipCheck.Id: true, rdsCheck.Id: true}},
+		{[]string{"severity>=high"}, map[string]bool{mfaCheck.Id: true, eipCheck.Id: false, rdsCheck.Id: false}},
+		{[]string{"severity>low"}, map[string]bool{mfaCheck.Id: true, eipCheck.Id: false, rdsCheck.Id: true}},
+		{[]string{"severity=Medium"}, map[string]bool{mfaCheck.Id: false, eipCheck.Id: false, rdsCheck.Id: true}},
+		{[]string{"severity<medium"}, map[string]bool{mfaCheck.Id: false, eipCheck.Id: true, rdsCheck.Id: false}},
+		{[]string{"category=security"}, map[string]bool{mfaCheck.Id: true, eipCheck.Id: false, rdsCheck.Id: false}},
+		{[]string{"category!=costoptimization"}, map[string]bool{mfaCheck.Id: true, eipCheck.Id: false, rdsCheck.Id: true}},
+		{[]string{"check=UnassociatedElasticIPAddresses"}, map[string]bool{mfaCheck.Id: false, eipCheck.Id: true, rdsCheck.Id: false}},
+		{[]string{"check=" + rdsCheck.Id}, map[string]bool{mfaCheck.Id: false, eipCheck.Id: false, rdsCheck.Id: true}},
+		{[]string{"category=security, check=RDSSingleAZInstances"}, map[string]bool{mfaCheck.Id: true, eipCheck.Id: false, rdsCheck.Id: true}},
+		{[]string{"category=costOptimization", "severity=critical"}, map[string]bool{mfaCheck.Id: true, eipCheck.Id: true, rdsCheck.Id: false}},
+	}
+
+	for _, c := range cases {
+		p, err := Parse(c.expressions)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %v: %s", c.expressions, err)
+		}
+		for _, check := range []common.Check{mfaCheck, eipCheck, rdsCheck} {
+			if got := p.Matches(check); got != c.want[check.Id] {
+				t.Errorf("Policy %v should match (%s): %t, Got %t", c.expressions, check.Id, c.want[check.Id], got)
+			}
+		}
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, expression := range []string{
+		"severity>=urgent",
+		"category=billing",
+		"category>=security",
+		"check>UnassociatedElasticIPAddresses",
+		"check=",
+		"region=us-east-1",
+		"high",
+	} {
+		if _, err := Parse([]string{expression}); err == nil {
+			t.Errorf("Expected an error parsing fail-on condition (%s).", expression)
+		}
+	}
+}
+
+func TestPolicy_Failures(t *testing.T) {
+	eipFinding := common.Finding{AccountId: "123456789011", Region: "us-east-1", ResourceId: "eipalloc-01"}
+	results := []common.Result{
+		newTestResult(mfaCheck),
+		newTestResult(eipCheck, eipFinding, eipFinding),
+	}
+
+	p, err := Parse([]string{"any"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failures := p.Failures(results); failures != 2 {
+		t.Fatalf("Policy should count 2 failures, Got %d", failures)
+	}
+
+	results[1].Suppress(common.SeverityLow, func(finding common.Finding) (common.SuppressedFinding, bool) {
+		return common.SuppressedFinding{Finding: finding, SuppressedBy: common.SuppressedByBaseline}, true
+	})
+	if failures := p.Failures(results); failures != 0 {
+		t.Fatalf("Suppressed findings should not count as failures, Got %d", failures)
+	}
+
+	p, err = Parse([]string{"severity>=high"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Matches(mfaCheck) || p.Failures(results) != 0 {
+		t.Fatal("A matching check without findings should not count as a failure.")
+	}
+}
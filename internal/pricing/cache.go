@@ -0,0 +1,91 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long a cached price is used before it is refreshed from the
+// pricing api. Expired prices are still used when the api cannot be reached.
+const cacheTTL = 30 * 24 * time.Hour
+
+type CacheEntry struct {
+	Price     float64   `json:"price"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// Cache is an on-disk store of prices keyed by the query that resolved them.
+type Cache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+// DefaultCachePath returns the location of the price cache in the user cache
+// directory.
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ckia", "pricing.json"), nil
+}
+
+// LoadCache reads the price cache at path. A missing file results in an empty
+// cache that is created on Save.
+func LoadCache(path string) (*Cache, error) {
+	cache := &Cache{
+		path:    path,
+		entries: map[string]CacheEntry{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// Get returns the cached price for key. Expired entries are only returned when
+// allowExpired is set.
+func (c *Cache) Get(key string, allowExpired bool) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || (!allowExpired && time.Since(entry.FetchedAt) > cacheTTL) {
+		return 0, false
+	}
+	return entry.Price, true
+}
+
+func (c *Cache) Set(key string, price float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = CacheEntry{Price: price, FetchedAt: time.Now()}
+}
+
+// Save writes the cache back to disk.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.MarshalIndent(c.entries, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
@@ -0,0 +1,381 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsPricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	// The pricing api is only served from a few regions, all of which return
+	// prices for every region.
+	ApiRegion = "us-east-1"
+
+	hoursPerMonth = 730
+
+	unitHours     = "Hrs"
+	unitGBMonth   = "GB-Mo"
+	currencyUSD   = "USD"
+	deploySingle  = "Single-AZ"
+	deployMultiAZ = "Multi-AZ"
+
+	// usageTypeIdleAddress is the usage type of a public IPv4 address that is
+	// not associated with a running resource.
+	usageTypeIdleAddress = "PublicIPv4:IdleAddress"
+)
+
+var errNoPrice = errors.New("no on demand price found")
+
+var rdsStorageVolumeTypes = map[string]string{
+	"gp2":      "General Purpose",
+	"gp3":      "General Purpose-GP3",
+	"io1":      "Provisioned IOPS",
+	"io2":      "Provisioned IOPS-IO2",
+	"standard": "Magnetic",
+}
+
+// rdsLicenseModels maps the license model of an RDS DB instance to the license
+// model of its price.
+var rdsLicenseModels = map[string]string{
+	"license-included":       "License included",
+	"bring-your-own-license": "Bring your own license",
+	"general-public-license": "No license required",
+	"postgresql-license":     "No license required",
+}
+
+// rdsDatabaseEditions maps the edition suffix of an Oracle or SQL Server engine
+// to the database edition of its price.
+var rdsDatabaseEditions = map[string]string{
+	"ee":  "Enterprise",
+	"se":  "Standard",
+	"se1": "Standard One",
+	"se2": "Standard Two",
+	"ex":  "Express",
+	"web": "Web",
+}
+
+var loadBalancerProductFamilies = map[string]string{
+	"application": "Load Balancer-Application",
+	"network":     "Load Balancer-Network",
+	"gateway":     "Load Balancer-Gateway",
+}
+
+// Estimator resolves on demand monthly prices in USD from the AWS Pricing API.
+// Prices are cached so repeated runs do not need to reach the api. A nil
+// Estimator estimates every price as zero.
+type Estimator struct {
+	api   awsPricing.GetProductsAPIClient
+	cache *Cache
+
+	mu  sync.Mutex
+	err error
+}
+
+func NewEstimator(api awsPricing.GetProductsAPIClient, cache *Cache) *Estimator {
+	return &Estimator{
+		api:   api,
+		cache: cache,
+	}
+}
+
+// Err returns the first error encountered while resolving a price. Prices that
+// could not be resolved are estimated as zero.
+func (e *Estimator) Err() error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+// RDSInstanceMonthlyCost returns the monthly cost of running an RDS DB instance.
+// The license model and the edition of Oracle and SQL Server engines select
+// the price, so license included instances are not priced as bring your own
+// license.
+func (e *Estimator) RDSInstanceMonthlyCost(ctx context.Context, region string, instanceClass string, engine string, licenseModel string, multiAZ bool) float64 {
+	filters := map[string]string{
+		"regionCode":       region,
+		"productFamily":    "Database Instance",
+		"instanceType":     instanceClass,
+		"databaseEngine":   rdsDatabaseEngine(engine),
+		"licenseModel":     rdsLicenseModel(licenseModel),
+		"deploymentOption": deploymentOption(multiAZ),
+	}
+	if edition := rdsDatabaseEdition(engine); edition != "" {
+		filters["databaseEdition"] = edition
+	}
+	hourly := e.price(ctx, query{
+		serviceCode: "AmazonRDS",
+		unit:        unitHours,
+		filters:     filters,
+	})
+	return common.RoundCents(hourly * hoursPerMonth)
+}
+
+// RDSStorageMonthlyCost returns the monthly cost of the storage allocated to an
+// RDS DB instance.
+func (e *Estimator) RDSStorageMonthlyCost(ctx context.Context, region string, storageType string, sizeInGB int, multiAZ bool) float64 {
+	perGB := e.price(ctx, query{
+		serviceCode: "AmazonRDS",
+		unit:        unitGBMonth,
+		filters: map[string]string{
+			"regionCode":       region,
+			"productFamily":    "Database Storage",
+			"volumeType":       rdsStorageVolumeTypes[storageType],
+			"deploymentOption": deploymentOption(multiAZ),
+		},
+	})
+	return common.RoundCents(perGB * float64(sizeInGB))
+}
+
+// EBSVolumeMonthlyCost returns the monthly storage cost of an EBS volume.
+func (e *Estimator) EBSVolumeMonthlyCost(ctx context.Context, region string, volumeType string, sizeInGB int) float64 {
+	perGB := e.price(ctx, query{
+		serviceCode: "AmazonEC2",
+		unit:        unitGBMonth,
+		filters: map[string]string{
+			"regionCode":    region,
+			"productFamily": "Storage",
+			"volumeApiName": volumeType,
+		},
+	})
+	return common.RoundCents(perGB * float64(sizeInGB))
+}
+
+// LoadBalancerMonthlyCost returns the fixed monthly cost of a load balancer of
+// the given elbv2 type. Usage based capacity unit charges are not included.
+func (e *Estimator) LoadBalancerMonthlyCost(ctx context.Context, region string, loadBalancerType string) float64 {
+	hourly := e.price(ctx, query{
+		serviceCode: "AWSELB",
+		unit:        unitHours,
+		filters: map[string]string{
+			"regionCode":    region,
+			"productFamily": loadBalancerProductFamilies[loadBalancerType],
+		},
+	})
+	return common.RoundCents(hourly * hoursPerMonth)
+}
+
+// ElasticIPAddressMonthlyCost returns the monthly cost of an idle Elastic IP
+// address. Public IPv4 addresses are priced under the AmazonVPC service code,
+// the IP Address products of AmazonEC2 only hold the ElasticIP usage types.
+// The product family is taken from the AWS price list documentation, no
+// recorded GetProducts response is available to confirm it.
+func (e *Estimator) ElasticIPAddressMonthlyCost(ctx context.Context, region string) float64 {
+	hourly := e.price(ctx, query{
+		serviceCode: "AmazonVPC",
+		unit:        unitHours,
+		usageType:   usageTypeIdleAddress,
+		filters: map[string]string{
+			"regionCode":    region,
+			"productFamily": "VPC Public IPv4 Address",
+		},
+	})
+	return common.RoundCents(hourly * hoursPerMonth)
+}
+
+// query describes a single on demand price to resolve. When several products
+// match the filters the lowest price is used.
+type query struct {
+	serviceCode string
+	unit        string
+	// usageType only keeps the products of a usage type, without the region
+	// prefix of the usage type, e.g. PublicIPv4:IdleAddress matches
+	// USE1-PublicIPv4:IdleAddress. The prefix differs by region, so it cannot
+	// be a filter of the pricing api.
+	usageType string
+	filters   map[string]string
+}
+
+func (q query) key() string {
+	names := make([]string, 0, len(q.filters))
+	for name := range q.filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{q.serviceCode, q.unit, q.usageType}
+	for _, name := range names {
+		parts = append(parts, name+"="+q.filters[name])
+	}
+	return strings.Join(parts, "|")
+}
+
+func (e *Estimator) price(ctx context.Context, q query) float64 {
+	if e == nil {
+		return 0
+	}
+
+	key := q.key()
+	if price, ok := e.cache.Get(key, false); ok {
+		return price
+	}
+
+	price, err := e.fetch(ctx, q)
+	if err != nil {
+		if price, ok := e.cache.Get(key, true); ok {
+			return price
+		}
+		e.recordErr(fmt.Errorf("unable to resolve price for (%s): %w", key, err))
+		return 0
+	}
+
+	e.cache.Set(key, price)
+	return price
+}
+
+func (e *Estimator) fetch(ctx context.Context, q query) (float64, error) {
+	in := &awsPricing.GetProductsInput{
+		ServiceCode: aws.String(q.serviceCode),
+	}
+	for name, value := range q.filters {
+		in.Filters = append(in.Filters, types.Filter{
+			Field: aws.String(name),
+			Type:  types.FilterTypeTermMatch,
+			Value: aws.String(value),
+		})
+	}
+
+	var prices []float64
+	paginator := awsPricing.NewGetProductsPaginator(e.api, in, func(o *awsPricing.GetProductsPaginatorOptions) {})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+
+		if err != nil {
+			return 0, err
+		}
+
+		for _, document := range output.PriceList {
+			documentPrices, err := expandOnDemandPrices(document, q.unit, q.usageType)
+			if err != nil {
+				return 0, err
+			}
+			prices = append(prices, documentPrices...)
+		}
+	}
+
+	if len(prices) == 0 {
+		return 0, errNoPrice
+	}
+
+	sort.Float64s(prices)
+	return prices[0], nil
+}
+
+func (e *Estimator) recordErr(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+type priceListDocument struct {
+	Product struct {
+		Attributes struct {
+			UsageType string `json:"usagetype"`
+		} `json:"attributes"`
+	} `json:"product"`
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string            `json:"unit"`
+				PricePerUnit map[string]string `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+// expandOnDemandPrices returns every non zero on demand USD price in a price
+// list document for the given unit. When usageType is set, documents of other
+// usage types have no prices.
+func expandOnDemandPrices(document string, unit string, usageType string) ([]float64, error) {
+	var priceList priceListDocument
+	if err := json.Unmarshal([]byte(document), &priceList); err != nil {
+		return nil, err
+	}
+	if usageType != "" && trimUsageTypeRegion(priceList.Product.Attributes.UsageType) != usageType {
+		return nil, nil
+	}
+
+	var prices []float64
+	for _, term := range priceList.Terms.OnDemand {
+		for _, dimension := range term.PriceDimensions {
+			if dimension.Unit != unit {
+				continue
+			}
+			price, err := strconv.ParseFloat(dimension.PricePerUnit[currencyUSD], 64)
+			if err != nil || price == 0 {
+				continue
+			}
+			prices = append(prices, price)
+		}
+	}
+	return prices, nil
+}
+
+// trimUsageTypeRegion removes the region prefix of a usage type, e.g. USE1- of
+// USE1-PublicIPv4:IdleAddress.
+func trimUsageTypeRegion(usageType string) string {
+	prefix, rest, found := strings.Cut(usageType, "-")
+	if found && !strings.Contains(prefix, ":") {
+		return rest
+	}
+	return usageType
+}
+
+func rdsLicenseModel(licenseModel string) string {
+	if model, ok := rdsLicenseModels[licenseModel]; ok {
+		return model
+	}
+	return "No license required"
+}
+
+// rdsDatabaseEdition returns the edition of Oracle and SQL Server engines, e.g.
+// Standard Two for oracle-se2-cdb, or an empty string for other engines.
+func rdsDatabaseEdition(engine string) string {
+	if !strings.HasPrefix(engine, "oracle-") && !strings.HasPrefix(engine, "sqlserver-") {
+		return ""
+	}
+	parts := strings.Split(engine, "-")
+	return rdsDatabaseEditions[parts[1]]
+}
+
+func rdsDatabaseEngine(engine string) string {
+	switch {
+	case engine == "aurora-mysql" || engine == "aurora":
+		return "Aurora MySQL"
+	case engine == "aurora-postgresql":
+		return "Aurora PostgreSQL"
+	case engine == "mysql":
+		return "MySQL"
+	case engine == "mariadb":
+		return "MariaDB"
+	case engine == "postgres":
+		return "PostgreSQL"
+	case strings.HasPrefix(engine, "oracle"):
+		return "Oracle"
+	case strings.HasPrefix(engine, "sqlserver"):
+		return "SQL Server"
+	}
+	return engine
+}
+
+func deploymentOption(multiAZ bool) string {
+	if multiAZ {
+		return deployMultiAZ
+	}
+	return deploySingle
+}
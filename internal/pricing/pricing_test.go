@@ -0,0 +1,184 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsPricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+const testEBSPriceDocument = `{
+	"product": {"productFamily": "Storage", "attributes": {"volumeApiName": "gp2", "regionCode": "us-east-1"}},
+	"terms": {
+		"OnDemand": {
+			"ABCDEFGH.JRTCKXETXF": {
+				"priceDimensions": {
+					"ABCDEFGH.JRTCKXETXF.6YS6EN2CT7": {
+						"unit": "GB-Mo",
+						"pricePerUnit": {"USD": "0.1000000000"}
+					}
+				}
+			}
+		}
+	}
+}`
+
+// testIPAddressPriceDocument returns the price document of a public IPv4
+// address usage type.
+func testIPAddressPriceDocument(usageType string, price string) string {
+	return `{
+	"product": {"productFamily": "VPC Public IPv4 Address", "attributes": {"usagetype": "` + usageType + `", "regionCode": "us-east-1"}},
+	"terms": {"OnDemand": {"ABCDEFGH.JRTCKXETXF": {"priceDimensions": {"ABCDEFGH.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "` + price + `"}}}}}}
+}`
+}
+
+type stubPricingApi struct {
+	calls     int
+	documents []string
+	err       error
+	// serviceCode and filters are the query of the last call.
+	serviceCode string
+	filters     map[string]string
+}
+
+func (s *stubPricingApi) GetProducts(ctx context.Context, in *awsPricing.GetProductsInput, optFns ...func(*awsPricing.Options)) (*awsPricing.GetProductsOutput, error) {
+	s.calls++
+	s.serviceCode = aws.ToString(in.ServiceCode)
+	s.filters = map[string]string{}
+	for _, filter := range in.Filters {
+		s.filters[aws.ToString(filter.Field)] = aws.ToString(filter.Value)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &awsPricing.GetProductsOutput{PriceList: s.documents}, nil
+}
+
+func TestExpandOnDemandPrices_basic(t *testing.T) {
+	prices, err := expandOnDemandPrices(testEBSPriceDocument, unitGBMonth, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(prices) != 1 || prices[0] != 0.1 {
+		t.Fatalf(`Expected a single price of 0.1, Got %v`, prices)
+	}
+}
+
+func TestExpandOnDemandPrices_otherUnit(t *testing.T) {
+	prices, err := expandOnDemandPrices(testEBSPriceDocument, unitHours, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(prices) != 0 {
+		t.Fatalf(`Expected no prices, Got %v`, prices)
+	}
+}
+
+func TestEstimatorEBSVolumeMonthlyCost_cached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	cache, err := LoadCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &stubPricingApi{documents: []string{testEBSPriceDocument}}
+	estimator := NewEstimator(api, cache)
+
+	if cost := estimator.EBSVolumeMonthlyCost(context.Background(), "us-east-1", "gp2", 20); cost != 2 {
+		t.Fatalf(`Monthly cost should be 2, Got %v`, cost)
+	}
+	if cost := estimator.EBSVolumeMonthlyCost(context.Background(), "us-east-1", "gp2", 50); cost != 5 {
+		t.Fatalf(`Monthly cost should be 5, Got %v`, cost)
+	}
+	if api.calls != 1 {
+		t.Fatalf(`Pricing api should be called once, Got %d`, api.calls)
+	}
+
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new run using the saved cache must not need the pricing api.
+	offlineCache, err := LoadCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offlineApi := &stubPricingApi{err: errors.New("no network")}
+	offlineEstimator := NewEstimator(offlineApi, offlineCache)
+
+	if cost := offlineEstimator.EBSVolumeMonthlyCost(context.Background(), "us-east-1", "gp2", 20); cost != 2 {
+		t.Fatalf(`Monthly cost should be 2, Got %v`, cost)
+	}
+	if offlineApi.calls != 0 {
+		t.Fatalf(`Pricing api should not be called, Got %d`, offlineApi.calls)
+	}
+	if offlineEstimator.Err() != nil {
+		t.Fatal(offlineEstimator.Err())
+	}
+}
+
+func TestEstimatorEBSVolumeMonthlyCost_error(t *testing.T) {
+	cache, err := LoadCache(filepath.Join(t.TempDir(), "pricing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimator := NewEstimator(&stubPricingApi{err: errors.New("access denied")}, cache)
+
+	if cost := estimator.EBSVolumeMonthlyCost(context.Background(), "us-east-1", "gp2", 20); cost != 0 {
+		t.Fatalf(`Monthly cost should be 0, Got %v`, cost)
+	}
+	if estimator.Err() == nil {
+		t.Fatal(`Estimator should report the pricing error`)
+	}
+}
+
+func TestEstimatorNil(t *testing.T) {
+	var estimator *Estimator
+
+	if cost := estimator.ElasticIPAddressMonthlyCost(context.Background(), "us-east-1"); cost != 0 {
+		t.Fatalf(`Monthly cost should be 0, Got %v`, cost)
+	}
+}
+
+func TestEstimatorElasticIPAddressMonthlyCost_usageType(t *testing.T) {
+	cache, err := LoadCache(filepath.Join(t.TempDir(), "pricing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &stubPricingApi{documents: []string{
+		testIPAddressPriceDocument("USE1-PublicIPv4:InUseAddress", "0.0040000000"),
+		testIPAddressPriceDocument("USE1-PublicIPv4:IdleAddress", "0.0050000000"),
+		testIPAddressPriceDocument("USE1-ElasticIP:AdditionalAddress", "0.0060000000"),
+	}}
+	estimator := NewEstimator(api, cache)
+
+	if cost := estimator.ElasticIPAddressMonthlyCost(context.Background(), "us-east-1"); cost != 3.65 {
+		t.Fatalf(`Monthly cost should be the idle address price of 3.65, Got %v`, cost)
+	}
+	if api.serviceCode != "AmazonVPC" || api.filters["productFamily"] != "VPC Public IPv4 Address" {
+		t.Fatalf(`Public IPv4 addresses should be priced by AmazonVPC, Got %s %v`, api.serviceCode, api.filters)
+	}
+}
+
+func TestEstimatorRDSInstanceMonthlyCost_licenseModel(t *testing.T) {
+	cache, err := LoadCache(filepath.Join(t.TempDir(), "pricing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &stubPricingApi{}
+	estimator := NewEstimator(api, cache)
+
+	estimator.RDSInstanceMonthlyCost(context.Background(), "us-east-1", "db.m5.large", "sqlserver-se", "license-included", false)
+	if api.filters["licenseModel"] != "License included" || api.filters["databaseEdition"] != "Standard" || api.filters["databaseEngine"] != "SQL Server" {
+		t.Fatalf(`Unexpected filters for a license included SQL Server instance, Got %v`, api.filters)
+	}
+
+	estimator.RDSInstanceMonthlyCost(context.Background(), "us-east-1", "db.m5.large", "postgres", "postgresql-license", false)
+	if _, ok := api.filters["databaseEdition"]; ok || api.filters["licenseModel"] != "No license required" {
+		t.Fatalf(`Unexpected filters for a PostgreSQL instance, Got %v`, api.filters)
+	}
+}
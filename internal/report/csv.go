@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const FormatCSV = "csv"
+
+var csvColumns = []string{
+	"checkId",
+	"category",
+	"severity",
+	"accountId",
+	"accountName",
+	"region",
+	"resourceId",
+	"resourceArn",
+	"reason",
+	"estimatedMonthlySavings",
+}
+
+// WriteCSV renders one row per flagged resource of every result. A tag:<key>
+// column is added for each of the tags option. With the detail option a column
+// is added for every check specific detail field and for the tags of the
+// resource.
+func WriteCSV(w io.Writer, results []common.Result, opts Options) error {
+	var detailColumns []string
+	if opts.Detail {
+		detailColumns = csvDetailColumns(results)
+	}
+
+	writer := csv.NewWriter(w)
+	header := append([]string{}, csvColumns...)
+	for _, tag := range opts.Tags {
+		header = append(header, "tag:"+tag)
+	}
+	if opts.Detail {
+		header = append(header, detailColumns...)
+		header = append(header, "tags")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		check := res.Metadata()
+		for _, finding := range res.Summary().Findings {
+			row := []string{
+				check.Id,
+				check.Category,
+				check.Severity,
+				finding.AccountId,
+				csvCell(finding.AccountName),
+				finding.Region,
+				csvCell(finding.ResourceId),
+				csvCell(finding.ResourceArn),
+				csvCell(finding.Reason),
+				strconv.FormatFloat(finding.EstimatedMonthlySavings, 'f', 2, 64),
+			}
+			for _, value := range tagValues(finding, opts.Tags) {
+				row = append(row, csvCell(value))
+			}
+			if opts.Detail {
+				for _, column := range detailColumns {
+					row = append(row, csvCell(finding.Metadata[column]))
+				}
+				row = append(row, csvCell(csvTags(finding.Tags)))
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvDetailColumns returns the sorted names of every detail field of the
+// findings in results.
+func csvDetailColumns(results []common.Result) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, res := range results {
+		for _, finding := range res.Summary().Findings {
+			for key := range finding.Metadata {
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func csvTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
+
+// csvCell prevents spreadsheet applications from evaluating a value as a
+// formula.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@") {
+		return "'" + value
+	}
+	return value
+}
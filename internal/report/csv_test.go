@@ -0,0 +1,102 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatCSV, &buf, newTestResults(), Options{}); err != nil {
+		t.Fatalf("Unexpected error writing csv: %s", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid csv: %s", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("Expected a header and 2 findings, Got %d rows", len(rows))
+	}
+
+	if len(rows[0]) != len(csvColumns) {
+		t.Fatalf("Expected %d columns without detail, Got %d", len(csvColumns), len(rows[0]))
+	}
+
+	expected := []string{"ckia:aws:cost:UnassociatedElasticIPAddresses", "costOptimization", "low", "123456789011", "", "us-east-1", "eipalloc-01", "arn:aws:ec2:us-east-1:123456789011:elastic-ip/eipalloc-01", "not associated", "3.60"}
+	for i, value := range expected {
+		if rows[1][i] != value {
+			t.Fatalf("Column %s should be %q, Got %q", csvColumns[i], value, rows[1][i])
+		}
+	}
+}
+
+func TestWriteCSV_detail(t *testing.T) {
+	results := newTestResults()
+	eips := results[1].(*common.StoredResult)
+	eips.Findings[0].Metadata = map[string]string{"ipAddress": "203.0.113.10"}
+	eips.Findings[0].Tags = map[string]string{"team": "platform", "environment": "production"}
+
+	var buf bytes.Buffer
+	if err := Write(FormatCSV, &buf, results, Options{Detail: true}); err != nil {
+		t.Fatalf("Unexpected error writing csv: %s", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid csv: %s", err)
+	}
+
+	header := rows[0]
+	if header[len(csvColumns)] != "ipAddress" || header[len(csvColumns)+1] != "tags" {
+		t.Fatalf("Expected ipAddress and tags detail columns, Got %v", header[len(csvColumns):])
+	}
+
+	if rows[1][len(csvColumns)] != "203.0.113.10" || rows[1][len(csvColumns)+1] != "environment=production;team=platform" {
+		t.Fatalf("Unexpected detail for eipalloc-01: %v", rows[1][len(csvColumns):])
+	}
+
+	if rows[2][len(csvColumns)] != "" {
+		t.Fatalf("Findings without the detail field should have an empty column, Got %q", rows[2][len(csvColumns)])
+	}
+}
+
+func TestWriteCSV_tags(t *testing.T) {
+	results := newTestResults()
+	eips := results[1].(*common.StoredResult)
+	eips.Findings[0].Tags = map[string]string{"owner": "platform", "cost-center": "1234"}
+
+	var buf bytes.Buffer
+	if err := Write(FormatCSV, &buf, results, Options{Tags: []string{"owner", "cost-center"}}); err != nil {
+		t.Fatalf("Unexpected error writing csv: %s", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid csv: %s", err)
+	}
+
+	if header := rows[0][len(csvColumns):]; len(header) != 2 || header[0] != "tag:owner" || header[1] != "tag:cost-center" {
+		t.Fatalf("Expected a column per tag, Got %v", header)
+	}
+	if tags := rows[1][len(csvColumns):]; tags[0] != "platform" || tags[1] != "1234" {
+		t.Fatalf("Unexpected tags for eipalloc-01: %v", tags)
+	}
+	if tags := rows[2][len(csvColumns):]; tags[0] != "" || tags[1] != "" {
+		t.Fatalf("Findings without the tags should have empty columns, Got %v", tags)
+	}
+}
+
+func TestCSVCell(t *testing.T) {
+	if csvCell("=HYPERLINK(\"http://example.com\")") != "'=HYPERLINK(\"http://example.com\")" {
+		t.Fatal("Formulas should be escaped.")
+	}
+
+	if csvCell(common.SeverityLow) != common.SeverityLow {
+		t.Fatal("Plain values should not be escaped.")
+	}
+}
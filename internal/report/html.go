@@ -0,0 +1,108 @@
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const FormatHTML = "html"
+
+//go:embed report.html.tmpl
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"savings": formatSavings,
+	"region":  formatRegion,
+}).Parse(htmlTemplateText))
+
+// htmlStatuses are the statuses in the order they are shown in the dashboard.
+var htmlStatuses = []string{
+	common.StatusError,
+	common.StatusWarning,
+	common.StatusOk,
+	common.StatusNotApplicable,
+	common.StatusFailedToRun,
+}
+
+// statusRank orders statuses from least to most severe, so the status of a
+// check across accounts and regions is the worst of its results.
+var statusRank = map[string]int{
+	common.StatusNotApplicable: 0,
+	common.StatusOk:            1,
+	common.StatusWarning:       2,
+	common.StatusFailedToRun:   3,
+	common.StatusError:         4,
+}
+
+type htmlReport struct {
+	Statuses   []string
+	Tags       []string
+	Savings    float64
+	Flagged    int
+	Suppressed int
+	Categories []htmlCategory
+}
+
+type htmlCategory struct {
+	Title        string
+	StatusCounts []int
+	Savings      float64
+	Checks       []*htmlCheck
+}
+
+type htmlCheck struct {
+	Check      common.Check
+	Status     string
+	Flagged    int
+	Suppressed int
+	Savings    float64
+	Results    []common.CheckResult
+	Findings   []common.Finding
+}
+
+// WriteHTML renders results as a single self contained html page with a
+// dashboard of check results by category and status, followed by a collapsible
+// section per check with its description, criteria, recommended action and a
+// sortable table of flagged resources.
+func WriteHTML(w io.Writer, results []common.Result, opts Options) error {
+	reported, grouped := resultsByCategory(results)
+	report := htmlReport{Statuses: htmlStatuses, Tags: opts.Tags, Savings: totalSavings(results)}
+
+	for _, c := range reported {
+		category := htmlCategory{Title: c.Title, StatusCounts: make([]int, len(htmlStatuses))}
+		checks := map[string]*htmlCheck{}
+		for _, res := range grouped[c.Id] {
+			summary := res.Summary()
+			for i, status := range htmlStatuses {
+				if summary.Status == status {
+					category.StatusCounts[i]++
+				}
+			}
+			category.Savings = common.RoundCents(category.Savings + summary.EstimatedMonthlySavings)
+
+			check, ok := checks[res.Metadata().Id]
+			if !ok {
+				check = &htmlCheck{Check: res.Metadata(), Status: summary.Status}
+				checks[check.Check.Id] = check
+				category.Checks = append(category.Checks, check)
+			}
+			if statusRank[summary.Status] > statusRank[check.Status] {
+				check.Status = summary.Status
+			}
+			check.Flagged += summary.ResourcesFlagged
+			check.Suppressed += len(summary.Suppressed)
+			check.Savings = common.RoundCents(check.Savings + summary.EstimatedMonthlySavings)
+			check.Results = append(check.Results, summary)
+			check.Findings = append(check.Findings, summary.Findings...)
+
+			report.Flagged += summary.ResourcesFlagged
+			report.Suppressed += len(summary.Suppressed)
+		}
+		report.Categories = append(report.Categories, category)
+	}
+
+	return htmlTemplate.Execute(w, report)
+}
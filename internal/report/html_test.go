@@ -0,0 +1,92 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatHTML, &buf, newTestResults(), Options{}); err != nil {
+		t.Fatalf("Unexpected error writing html: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		`<div class="value">$3.60</div>`,
+		`<div class="value">2</div>`,
+		`<tr><td>Cost Optimization</td><td class="number">0</td><td class="number">1</td><td class="number">0</td><td class="number">0</td><td class="number">0</td><td class="number">$3.60</td></tr>`,
+		`<tr><td>Security</td><td class="number">1</td>`,
+		"Release the Elastic IP address.",
+		"<code>eipalloc-01</code>",
+		`<span class="status status-error">error</span> Root Account Missing MFA`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Html should contain %q, Got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWriteHTML_tags(t *testing.T) {
+	results := newTestResults()
+	eips := results[1].(*common.StoredResult)
+	eips.Findings[0].Tags = map[string]string{"owner": "platform"}
+
+	var buf bytes.Buffer
+	if err := Write(FormatHTML, &buf, results, Options{Tags: []string{"owner"}}); err != nil {
+		t.Fatalf("Unexpected error writing html: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		`<th class="sortable">Region</th><th class="sortable">owner</th><th class="sortable">Reason</th>`,
+		`<td>us-east-1</td><td>platform</td><td>not associated</td>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Html should contain %q, Got:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWriteHTML_escapesFindings(t *testing.T) {
+	results := newTestResults()
+	eips := results[1].(*common.StoredResult)
+	eips.Findings[0].Reason = "<script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	if err := Write(FormatHTML, &buf, results, Options{}); err != nil {
+		t.Fatalf("Unexpected error writing html: %s", err)
+	}
+
+	if strings.Contains(buf.String(), "<script>alert(1)</script>") {
+		t.Fatal("Finding values should be escaped.")
+	}
+}
+
+func TestWriteHTML_worstStatus(t *testing.T) {
+	ok := &common.StoredResult{
+		Check:       common.Check{Id: "ckia:aws:cost:IdleLoadBalancers", Category: common.CategoryCostOptimization, Name: "Idle Load Balancers"},
+		CheckResult: common.NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	ok.ResourcesEvaluated = 1
+	ok.Evaluate(common.SeverityLow)
+	warning := &common.StoredResult{
+		Check:       ok.Check,
+		CheckResult: common.NewCheckResult("123456789011", "us-west-2", nil),
+	}
+	warning.ResourcesEvaluated = 1
+	warning.AddFinding(common.Finding{ResourceId: "app/idle/1", AccountId: "123456789011", Region: "us-west-2"})
+	warning.Evaluate(common.SeverityLow)
+
+	var buf bytes.Buffer
+	if err := Write(FormatHTML, &buf, []common.Result{ok, warning}, Options{}); err != nil {
+		t.Fatalf("Unexpected error writing html: %s", err)
+	}
+
+	if !strings.Contains(buf.String(), `<span class="status status-warning">warning</span> Idle Load Balancers`) {
+		t.Fatalf("Check status should be the worst status of its results, Got:\n%s", buf.String())
+	}
+}
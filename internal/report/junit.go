@@ -0,0 +1,153 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const FormatJUnit = "junit"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit renders results as JUnit xml with a test suite per category and a
+// test case per check run. A check run with flagged resources fails with one
+// line per resource, a check run that could not run is an error, and check
+// runs without resources to evaluate and excluded checks are skipped.
+func WriteJUnit(w io.Writer, results []common.Result, opts Options) error {
+	suites := junitTestSuites{Name: "ckia"}
+	_, grouped := resultsByCategory(results)
+	excluded := map[string][]common.Check{}
+	for _, check := range opts.Excluded {
+		excluded[check.Category] = append(excluded[check.Category], check)
+	}
+
+	for _, c := range categories {
+		if len(grouped[c.Id]) == 0 && len(excluded[c.Id]) == 0 {
+			continue
+		}
+		suite := junitTestSuite{Name: c.Title}
+		for _, res := range grouped[c.Id] {
+			suite.Cases = append(suite.Cases, expandJUnitTestCase(res.Metadata(), res.Summary()))
+		}
+		for _, check := range excluded[c.Id] {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      check.ShortName(),
+				Classname: check.Id,
+				Skipped:   &junitMessage{Message: "check excluded from the run"},
+			})
+		}
+
+		for _, testCase := range suite.Cases {
+			suite.Tests++
+			switch {
+			case testCase.Failure != nil:
+				suite.Failures++
+			case testCase.Error != nil:
+				suite.Errors++
+			case testCase.Skipped != nil:
+				suite.Skipped++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func expandJUnitTestCase(check common.Check, summary common.CheckResult) junitTestCase {
+	testCase := junitTestCase{
+		Name:      fmt.Sprintf("%s [%s/%s]", check.ShortName(), summary.AccountId, formatRegion(summary.Region)),
+		Classname: check.Id,
+	}
+
+	switch {
+	case summary.Status == common.StatusFailedToRun:
+		testCase.Error = &junitMessage{Message: "check failed to run", Text: summary.Error}
+	case len(summary.Findings) > 0:
+		var lines []string
+		for _, finding := range summary.Findings {
+			lines = append(lines, junitFindingLine(finding, finding.Reason))
+		}
+		testCase.Failure = &junitMessage{
+			Message: fmt.Sprintf("%d resource(s) flagged by %s", len(summary.Findings), check.Name),
+			Type:    check.Severity,
+			Text:    strings.Join(lines, "\n"),
+		}
+	case summary.Status == common.StatusNotApplicable:
+		testCase.Skipped = &junitMessage{Message: "no resources to evaluate"}
+	}
+
+	if len(summary.Suppressed) > 0 {
+		var lines []string
+		for _, suppressed := range summary.Suppressed {
+			reason := suppressed.SuppressedBy
+			if suppressed.SuppressionReason != "" {
+				reason = fmt.Sprintf("%s: %s", suppressed.SuppressedBy, suppressed.SuppressionReason)
+			}
+			lines = append(lines, "suppressed "+junitFindingLine(suppressed.Finding, reason))
+		}
+		testCase.SystemOut = strings.Join(lines, "\n")
+	}
+	return testCase
+}
+
+func junitFindingLine(finding common.Finding, detail string) string {
+	resource := finding.ResourceId
+	if finding.ResourceArn != "" {
+		resource = finding.ResourceArn
+	}
+	line := fmt.Sprintf("%s: %s", resource, detail)
+	if finding.EstimatedMonthlySavings != 0 {
+		line += fmt.Sprintf(" (estimated savings %s/month)", formatSavings(finding.EstimatedMonthlySavings))
+	}
+	return line
+}
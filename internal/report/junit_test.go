@@ -0,0 +1,81 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	results := newTestResults()
+	notApplicable := &common.StoredResult{
+		Check:       common.Check{Id: "ckia:aws:cost:IdleDBInstances", Category: common.CategoryCostOptimization, Severity: common.SeverityMedium, Name: "RDS Idle DB Instances"},
+		CheckResult: common.NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	notApplicable.Evaluate(notApplicable.Severity)
+	results = append(results, notApplicable)
+	excluded := common.Check{Id: "ckia:aws:faulttolerance:RDSSingleAZInstances", Category: common.CategoryFaultTolerance}
+
+	var buf bytes.Buffer
+	if err := Write(FormatJUnit, &buf, results, Options{Excluded: []common.Check{excluded}}); err != nil {
+		t.Fatalf("Unexpected error writing junit: %s", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("Output is not valid xml: %s", err)
+	}
+
+	if suites.Tests != 4 || suites.Failures != 2 || suites.Skipped != 2 || suites.Errors != 0 {
+		t.Fatalf("Expected 4 tests with 2 failures and 2 skipped, Got %d tests, %d failures, %d skipped, %d errors", suites.Tests, suites.Failures, suites.Skipped, suites.Errors)
+	}
+
+	if len(suites.Suites) != 3 || suites.Suites[0].Name != "Cost Optimization" || suites.Suites[1].Name != "Security" || suites.Suites[2].Name != "Fault Tolerance" {
+		t.Fatalf("Unexpected test suites: %v", suites.Suites)
+	}
+
+	idle := suites.Suites[0].Cases[0]
+	if idle.Name != "IdleDBInstances [123456789011/us-east-1]" || idle.Skipped == nil {
+		t.Fatalf("Not applicable check run should be skipped, Got %v", idle)
+	}
+
+	eips := suites.Suites[0].Cases[1]
+	if eips.Failure == nil || !strings.Contains(eips.Failure.Text, "arn:aws:ec2:us-east-1:123456789011:elastic-ip/eipalloc-01: not associated") {
+		t.Fatalf("Check run with flagged resources should fail with a line per resource, Got %v", eips.Failure)
+	}
+
+	if !strings.Contains(eips.SystemOut, "suppressed eipalloc-02: suppression: partner allowlist") {
+		t.Fatalf("Suppressed findings should be in system-out, Got %q", eips.SystemOut)
+	}
+
+	rds := suites.Suites[2].Cases[0]
+	if rds.Classname != excluded.Id || rds.Skipped == nil || rds.Skipped.Message != "check excluded from the run" {
+		t.Fatalf("Excluded check should be skipped, Got %v", rds)
+	}
+}
+
+func TestWriteJUnit_failedToRun(t *testing.T) {
+	failed := &common.StoredResult{
+		Check:       common.Check{Id: "ckia:aws:cost:IdleDBInstances", Category: common.CategoryCostOptimization},
+		CheckResult: common.NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	failed.Status = common.StatusFailedToRun
+	failed.Error = "AccessDenied"
+
+	var buf bytes.Buffer
+	if err := Write(FormatJUnit, &buf, []common.Result{failed}, Options{}); err != nil {
+		t.Fatalf("Unexpected error writing junit: %s", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("Output is not valid xml: %s", err)
+	}
+
+	if suites.Errors != 1 || suites.Suites[0].Cases[0].Error.Text != "AccessDenied" {
+		t.Fatalf("Check that failed to run should be an error, Got %v", suites.Suites[0].Cases[0])
+	}
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const FormatMarkdown = "markdown"
+
+// WriteMarkdown renders results as a markdown report suitable for a ticket or
+// wiki page. Results are grouped by category with a summary table per category
+// followed by the flagged and suppressed resources.
+func WriteMarkdown(w io.Writer, results []common.Result, opts Options) error {
+	reported, grouped := resultsByCategory(results)
+
+	fmt.Fprintf(w, "# ckia report\n\n")
+	fmt.Fprintf(w, "**Estimated monthly savings:** $%.2f\n", totalSavings(results))
+
+	for _, c := range reported {
+		fmt.Fprintf(w, "\n## %s\n\n", c.Title)
+		fmt.Fprintln(w, "| Check | Account | Region | Status | Evaluated | Flagged | Suppressed | Savings |")
+		fmt.Fprintln(w, "|---|---|---|---|---:|---:|---:|---:|")
+		var findings, suppressed int
+		for _, res := range grouped[c.Id] {
+			summary := res.Summary()
+			findings += len(summary.Findings)
+			suppressed += len(summary.Suppressed)
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %d | %d | %d | %s |\n",
+				markdownCell(res.Metadata().Name),
+				summary.AccountId,
+				formatRegion(summary.Region),
+				summary.Status,
+				summary.ResourcesEvaluated,
+				summary.ResourcesFlagged,
+				len(summary.Suppressed),
+				formatSavings(summary.EstimatedMonthlySavings),
+			)
+		}
+
+		if findings > 0 {
+			fmt.Fprintf(w, "\n### Findings\n\n")
+			writeMarkdownFindingHeader(w, "Reason", opts.Tags)
+			for _, res := range grouped[c.Id] {
+				for _, finding := range res.Summary().Findings {
+					writeMarkdownFinding(w, res.Metadata(), finding, finding.Reason, opts.Tags)
+				}
+			}
+		}
+
+		if suppressed > 0 {
+			fmt.Fprintf(w, "\n### Suppressed\n\n")
+			writeMarkdownFindingHeader(w, "Suppressed By", opts.Tags)
+			for _, res := range grouped[c.Id] {
+				for _, s := range res.Summary().Suppressed {
+					suppressedBy := s.SuppressedBy
+					if s.SuppressionReason != "" {
+						suppressedBy = fmt.Sprintf("%s: %s", s.SuppressedBy, s.SuppressionReason)
+					}
+					writeMarkdownFinding(w, res.Metadata(), s.Finding, suppressedBy, opts.Tags)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeMarkdownFindingHeader writes the header of a findings table with a
+// column for each of the tag keys.
+func writeMarkdownFindingHeader(w io.Writer, detail string, tags []string) {
+	fmt.Fprint(w, "| Check | Resource | Account | Region | ")
+	for _, tag := range tags {
+		fmt.Fprintf(w, "%s | ", markdownCell(tag))
+	}
+	fmt.Fprintf(w, "%s | Savings |\n", detail)
+	fmt.Fprintf(w, "|---|---|---|---|%s---|---:|\n", strings.Repeat("---|", len(tags)))
+}
+
+func writeMarkdownFinding(w io.Writer, check common.Check, finding common.Finding, detail string, tags []string) {
+	resource := "`" + finding.ResourceId + "`"
+	if finding.ResourceArn != "" {
+		resource = fmt.Sprintf("`%s`<br>`%s`", finding.ResourceId, finding.ResourceArn)
+	}
+	account := finding.AccountId
+	if finding.AccountName != "" {
+		account = fmt.Sprintf("%s (%s)", finding.AccountId, markdownCell(finding.AccountName))
+	}
+	var tagCells strings.Builder
+	for _, value := range tagValues(finding, tags) {
+		tagCells.WriteString(markdownCell(value) + " | ")
+	}
+	fmt.Fprintf(w, "| %s | %s | %s | %s | %s%s | %s |\n",
+		check.ShortName(),
+		resource,
+		account,
+		formatRegion(finding.Region),
+		tagCells.String(),
+		markdownCell(detail),
+		formatSavings(finding.EstimatedMonthlySavings),
+	)
+}
+
+// markdownCell escapes text for use in a markdown table cell.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
@@ -0,0 +1,63 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatMarkdown, &buf, newTestResults(), Options{}); err != nil {
+		t.Fatalf("Unexpected error writing markdown: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"**Estimated monthly savings:** $3.60",
+		"## Cost Optimization",
+		"| Unassociated Elastic IP Addresses | 123456789011 | us-east-1 | warning | 2 | 1 | 1 | $3.60 |",
+		"| UnassociatedElasticIPAddresses | `eipalloc-01`<br>`arn:aws:ec2:us-east-1:123456789011:elastic-ip/eipalloc-01` | 123456789011 | us-east-1 | not associated | $3.60 |",
+		"| UnassociatedElasticIPAddresses | `eipalloc-02` | 123456789011 | us-east-1 | suppression: partner allowlist | - |",
+		"## Security",
+		"| Root Account Missing MFA | 123456789011 | global | error | 1 | 1 | 0 | - |",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Markdown should contain %q, Got:\n%s", expected, out)
+		}
+	}
+
+	if strings.Contains(out, "## Performance") {
+		t.Fatal("Categories without results should be omitted.")
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	if markdownCell("a|b\nc") != "a\\|b c" {
+		t.Fatalf("Unexpected escaped cell, Got %q", markdownCell("a|b\nc"))
+	}
+}
+
+func TestWriteMarkdown_tags(t *testing.T) {
+	results := newTestResults()
+	eips := results[1].(*common.StoredResult)
+	eips.Findings[0].Tags = map[string]string{"owner": "platform"}
+
+	var buf bytes.Buffer
+	if err := Write(FormatMarkdown, &buf, results, Options{Tags: []string{"owner"}}); err != nil {
+		t.Fatalf("Unexpected error writing markdown: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"| Check | Resource | Account | Region | owner | Reason | Savings |\n|---|---|---|---|---|---|---:|",
+		"| us-east-1 | platform | not associated | $3.60 |",
+		"| us-east-1 |  | suppression: partner allowlist | - |",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Markdown should contain %q, Got:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+// Writer renders check results in an output format.
+type Writer func(w io.Writer, results []common.Result, opts Options) error
+
+// Options are settings shared by the output formats. Formats ignore the
+// options that do not apply to them.
+type Options struct {
+	// Detail includes the check specific detail of every finding.
+	Detail bool
+	// Excluded are the checks that were not selected to run.
+	Excluded []common.Check
+	// Tags are tag keys, e.g. owner, shown in a column of their own for every
+	// finding.
+	Tags []string
+}
+
+var writers = map[string]Writer{
+	FormatSARIF:    WriteSARIF,
+	FormatTable:    WriteTable,
+	FormatMarkdown: WriteMarkdown,
+	FormatCSV:      WriteCSV,
+	FormatHTML:     WriteHTML,
+	FormatJUnit:    WriteJUnit,
+}
+
+// category is a check category in the order and with the title it is
+// reported under.
+type category struct {
+	Id    string
+	Title string
+}
+
+var categories = []category{
+	{Id: common.CategoryCostOptimization, Title: "Cost Optimization"},
+	{Id: common.CategoryPerformance, Title: "Performance"},
+	{Id: common.CategorySecurity, Title: "Security"},
+	{Id: common.CategoryFaultTolerance, Title: "Fault Tolerance"},
+	{Id: common.CategoryServiceLimits, Title: "Service Limits"},
+}
+
+// Formats returns the names of the output formats supported by Write.
+func Formats() []string {
+	formats := make([]string, 0, len(writers))
+	for format := range writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Write renders results to w in format. Results are ordered by check id,
+// account and region so the output is stable between runs.
+func Write(format string, w io.Writer, results []common.Result, opts Options) error {
+	writer, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	return writer(w, SortResults(results), opts)
+}
+
+// SortResults returns a copy of results ordered by check id, account and
+// region.
+func SortResults(results []common.Result) []common.Result {
+	sorted := make([]common.Result, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Metadata().Id != b.Metadata().Id {
+			return a.Metadata().Id < b.Metadata().Id
+		}
+		if a.Summary().AccountId != b.Summary().AccountId {
+			return a.Summary().AccountId < b.Summary().AccountId
+		}
+		return a.Summary().Region < b.Summary().Region
+	})
+	return sorted
+}
+
+// resultsByCategory returns the results of each category in report order.
+// Categories without results are omitted.
+func resultsByCategory(results []common.Result) ([]category, map[string][]common.Result) {
+	grouped := map[string][]common.Result{}
+	for _, res := range results {
+		grouped[res.Metadata().Category] = append(grouped[res.Metadata().Category], res)
+	}
+	var reported []category
+	for _, c := range categories {
+		if len(grouped[c.Id]) > 0 {
+			reported = append(reported, c)
+		}
+	}
+	return reported, grouped
+}
+
+// totalSavings returns the estimated monthly savings of every result.
+func totalSavings(results []common.Result) float64 {
+	var total float64
+	for _, res := range results {
+		total = common.RoundCents(total + res.Summary().EstimatedMonthlySavings)
+	}
+	return total
+}
+
+// formatSavings formats savings in USD, or a dash when there are none.
+func formatSavings(savings float64) string {
+	if savings == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.2f", savings)
+}
+
+// tagValues returns the value of each of the tag keys on a finding, or an empty
+// string for tags the resource does not have.
+func tagValues(finding common.Finding, keys []string) []string {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = finding.Tags[key]
+	}
+	return values
+}
+
+// formatRegion returns the region of a result or finding, or global for global
+// checks.
+func formatRegion(region string) string {
+	if region == "" {
+		return "global"
+	}
+	return region
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func newTestResults() []common.Result {
+	eips := &common.StoredResult{
+		Check: common.Check{
+			Id:                  "ckia:aws:cost:UnassociatedElasticIPAddresses",
+			Category:            common.CategoryCostOptimization,
+			Severity:            common.SeverityLow,
+			Name:                "Unassociated Elastic IP Addresses",
+			Description:         "Checks for Elastic IP addresses that are not associated with a running instance.",
+			Criteria:            "Any Elastic IP address that is not associated.",
+			RecommendedAction:   "Release the Elastic IP address.",
+			AdditionalResources: "https://docs.aws.amazon.com/awssupport/latest/user/cost-optimization-checks.html",
+		},
+		CheckResult: common.NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	eips.ResourcesEvaluated = 2
+	eips.AddFinding(common.Finding{
+		ResourceId:              "eipalloc-01",
+		ResourceArn:             "arn:aws:ec2:us-east-1:123456789011:elastic-ip/eipalloc-01",
+		AccountId:               "123456789011",
+		Region:                  "us-east-1",
+		Reason:                  "not associated",
+		EstimatedMonthlySavings: 3.6,
+	})
+	eips.Evaluate(eips.Severity)
+	eips.Suppressed = []common.SuppressedFinding{{
+		Finding: common.Finding{
+			ResourceId: "eipalloc-02",
+			AccountId:  "123456789011",
+			Region:     "us-east-1",
+			Reason:     "not associated",
+		},
+		SuppressedBy:      common.SuppressedBySuppression,
+		SuppressionReason: "partner allowlist",
+	}}
+
+	mfa := &common.StoredResult{
+		Check: common.Check{
+			Id:                "ckia:aws:security:RootAccountMissingMFA",
+			Category:          common.CategorySecurity,
+			Severity:          common.SeverityCritical,
+			Name:              "Root Account Missing MFA",
+			Description:       "Checks the root account for MFA.",
+			Criteria:          "MFA is not enabled on the root account.",
+			RecommendedAction: "Enable MFA on the root account.",
+			Global:            true,
+		},
+		CheckResult: common.NewCheckResult("123456789011", "", nil),
+	}
+	mfa.ResourcesEvaluated = 1
+	mfa.AddFinding(common.Finding{
+		ResourceId:  "123456789011",
+		ResourceArn: "arn:aws:iam::123456789011:root",
+		AccountId:   "123456789011",
+		Reason:      "root account does not have MFA enabled",
+	})
+	mfa.Evaluate(mfa.Severity)
+
+	return []common.Result{mfa, eips}
+}
+
+func TestSortResults(t *testing.T) {
+	sorted := SortResults(newTestResults())
+
+	if sorted[0].Metadata().Id != "ckia:aws:cost:UnassociatedElasticIPAddresses" {
+		t.Fatalf("Results should be sorted by check id, Got %s first", sorted[0].Metadata().Id)
+	}
+}
+
+func TestWrite_unsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write("yaml", &buf, newTestResults(), Options{}); err == nil {
+		t.Fatal("Expected an error for an unsupported format.")
+	}
+}
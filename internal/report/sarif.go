@@ -0,0 +1,218 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	FormatSARIF = "sarif"
+
+	sarifVersion        = "2.1.0"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName       = "ckia"
+	sarifInformationUri = "https://github.com/brittandeyoung/ckia"
+	sarifFingerprintKey = "ckiaFindingId/v1"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	FullDescription      sarifMessage           `json:"fullDescription"`
+	Help                 sarifMessage           `json:"help"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifResult struct {
+	RuleId              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	Properties          map[string]string  `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// WriteSARIF renders results as a SARIF 2.1.0 log. Every check that was run is
+// a rule and every finding is a result located at the arn of the resource.
+// Suppressed findings are included as results with an external suppression.
+func WriteSARIF(w io.Writer, results []common.Result, opts Options) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           sarifToolName,
+			InformationUri: sarifInformationUri,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := map[string]int{}
+	for _, res := range results {
+		check := res.Metadata()
+		index, ok := ruleIndex[check.Id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[check.Id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, expandSARIFRule(check))
+		}
+
+		summary := res.Summary()
+		for _, finding := range summary.Findings {
+			run.Results = append(run.Results, expandSARIFResult(check, index, finding))
+		}
+		for _, suppressed := range summary.Suppressed {
+			result := expandSARIFResult(check, index, suppressed.Finding)
+			result.Suppressions = []sarifSuppression{{Kind: "external", Justification: suppressed.SuppressionReason}}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+func expandSARIFRule(check common.Check) sarifRule {
+	help := fmt.Sprintf("%s\n\nCriteria: %s\n\n%s", check.RecommendedAction, check.Criteria, check.AdditionalResources)
+	helpMarkdown := fmt.Sprintf("**Recommended Action**\n\n%s\n\n**Criteria**\n\n%s\n\n**Additional Resources**\n\n%s", check.RecommendedAction, check.Criteria, check.AdditionalResources)
+	properties := map[string]interface{}{
+		"category": check.Category,
+		"severity": check.Severity,
+		"tags":     []string{check.Category},
+	}
+	if check.Category == common.CategorySecurity {
+		properties["tags"] = []string{check.Category, "security"}
+		properties["security-severity"] = sarifSecuritySeverity(check.Severity)
+	}
+	return sarifRule{
+		Id:                   check.Id,
+		Name:                 check.ShortName(),
+		ShortDescription:     sarifMessage{Text: check.Name},
+		FullDescription:      sarifMessage{Text: check.Description},
+		Help:                 sarifMessage{Text: help, Markdown: helpMarkdown},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(check.Severity)},
+		Properties:           properties,
+	}
+}
+
+func expandSARIFResult(check common.Check, ruleIndex int, finding common.Finding) sarifResult {
+	name := finding.ResourceArn
+	if name == "" {
+		name = finding.ResourceId
+	}
+	properties := map[string]string{
+		"accountId": finding.AccountId,
+	}
+	if finding.AccountName != "" {
+		properties["accountName"] = finding.AccountName
+	}
+	if finding.Region != "" {
+		properties["region"] = finding.Region
+	}
+	if finding.EstimatedMonthlySavings != 0 {
+		properties["estimatedMonthlySavings"] = fmt.Sprintf("%.2f", finding.EstimatedMonthlySavings)
+	}
+	return sarifResult{
+		RuleId:    check.Id,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevel(check.Severity),
+		Message:   sarifMessage{Text: fmt.Sprintf("%s (%s): %s", finding.ResourceId, check.Name, finding.Reason)},
+		Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+			Name:               finding.ResourceId,
+			FullyQualifiedName: name,
+			Kind:               "resource",
+		}}}},
+		PartialFingerprints: map[string]string{sarifFingerprintKey: findingFingerprint(check, finding)},
+		Properties:          properties,
+	}
+}
+
+// findingFingerprint identifies a finding across runs.
+func findingFingerprint(check common.Check, finding common.Finding) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{check.Id, finding.AccountId, finding.Region, finding.ResourceId}, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case common.SeverityHigh, common.SeverityCritical:
+		return "error"
+	case common.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps a severity to the score GitHub code scanning uses
+// to rank security results.
+func sarifSecuritySeverity(severity string) string {
+	switch severity {
+	case common.SeverityCritical:
+		return "9.5"
+	case common.SeverityHigh:
+		return "7.5"
+	case common.SeverityMedium:
+		return "5.0"
+	default:
+		return "3.0"
+	}
+}
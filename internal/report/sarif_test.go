@@ -0,0 +1,59 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatSARIF, &buf, newTestResults(), Options{}); err != nil {
+		t.Fatalf("Unexpected error writing sarif: %s", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Output is not valid json: %s", err)
+	}
+
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("Expected a single SARIF %s run, Got version %s with %d runs", sarifVersion, log.Version, len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("Expected 2 rules, Got %d", len(run.Tool.Driver.Rules))
+	}
+
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected 3 results, Got %d", len(run.Results))
+	}
+
+	eip := run.Results[0]
+	if eip.RuleId != "ckia:aws:cost:UnassociatedElasticIPAddresses" || eip.Level != "note" {
+		t.Fatalf("Unexpected result for eipalloc-01: %v", eip)
+	}
+
+	if eip.Locations[0].LogicalLocations[0].FullyQualifiedName != "arn:aws:ec2:us-east-1:123456789011:elastic-ip/eipalloc-01" {
+		t.Fatalf("Result should be located at the resource arn, Got %s", eip.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	}
+
+	suppressed := run.Results[1]
+	if len(suppressed.Suppressions) != 1 || suppressed.Suppressions[0].Justification != "partner allowlist" {
+		t.Fatalf("eipalloc-02 should be suppressed, Got %v", suppressed.Suppressions)
+	}
+
+	if suppressed.Locations[0].LogicalLocations[0].FullyQualifiedName != "eipalloc-02" {
+		t.Fatalf("Result without an arn should be located at the resource id, Got %s", suppressed.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	}
+
+	mfa := run.Results[2]
+	if mfa.RuleIndex != 1 || mfa.Level != "error" {
+		t.Fatalf("Unexpected result for the root account: %v", mfa)
+	}
+
+	if run.Tool.Driver.Rules[1].Properties["security-severity"] != "9.5" {
+		t.Fatalf("Security rules should have a security-severity, Got %v", run.Tool.Driver.Rules[1].Properties["security-severity"])
+	}
+}
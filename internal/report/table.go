@@ -0,0 +1,126 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const FormatTable = "table"
+
+const colorReset = "\033[0m"
+
+var statusColors = map[string]string{
+	common.StatusOk:            "\033[32m",
+	common.StatusWarning:       "\033[33m",
+	common.StatusError:         "\033[31m",
+	common.StatusNotApplicable: "\033[90m",
+	common.StatusFailedToRun:   "\033[35m",
+}
+
+// WriteTable renders results as a table for terminals. Results are grouped by
+// category with a summary row per check run followed by the flagged
+// resources. Statuses are colored when w is a terminal and NO_COLOR is unset.
+func WriteTable(w io.Writer, results []common.Result, opts Options) error {
+	color := isTerminal(w)
+	reported, grouped := resultsByCategory(results)
+
+	for _, c := range reported {
+		fmt.Fprintf(w, "%s\n\n", c.Title)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHECK\tACCOUNT\tREGION\tEVALUATED\tFLAGGED\tSUPPRESSED\tSAVINGS\tSTATUS")
+		var findings int
+		for _, res := range grouped[c.Id] {
+			summary := res.Summary()
+			findings += len(summary.Findings)
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\n",
+				res.Metadata().ShortName(),
+				summary.AccountId,
+				formatRegion(summary.Region),
+				summary.ResourcesEvaluated,
+				summary.ResourcesFlagged,
+				len(summary.Suppressed),
+				formatSavings(summary.EstimatedMonthlySavings),
+				colorStatus(summary.Status, color),
+			)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if findings > 0 {
+			fmt.Fprintln(w)
+			tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tw, "  CHECK\tRESOURCE\tACCOUNT\tREGION\tSAVINGS\t%sREASON\n", tableTagColumns(opts.Tags, strings.ToUpper))
+			for _, res := range grouped[c.Id] {
+				for _, finding := range res.Summary().Findings {
+					fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s%s\n",
+						res.Metadata().ShortName(),
+						finding.ResourceId,
+						finding.AccountId,
+						formatRegion(finding.Region),
+						formatSavings(finding.EstimatedMonthlySavings),
+						tableTagColumns(tagValues(finding, opts.Tags), formatTag),
+						finding.Reason,
+					)
+				}
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	_, err := fmt.Fprintf(w, "Estimated monthly savings: $%.2f\n", totalSavings(results))
+	return err
+}
+
+// tableTagColumns returns a tab terminated column for each value, formatted
+// with format.
+func tableTagColumns(values []string, format func(string) string) string {
+	var columns strings.Builder
+	for _, value := range values {
+		columns.WriteString(format(value) + "\t")
+	}
+	return columns.String()
+}
+
+// formatTag returns the value of a tag, or a dash when the resource does not
+// have the tag.
+func formatTag(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// colorStatus wraps status in the color for the status. The status is the last
+// column of the table, so the escape codes do not affect alignment.
+func colorStatus(status string, color bool) string {
+	code, ok := statusColors[status]
+	if !color || !ok {
+		return status
+	}
+	return code + status + colorReset
+}
+
+func isTerminal(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(FormatTable, &buf, newTestResults(), Options{}); err != nil {
+		t.Fatalf("Unexpected error writing table: %s", err)
+	}
+	out := buf.String()
+
+	if strings.Index(out, "Cost Optimization") > strings.Index(out, "Security") {
+		t.Fatal("Categories should be in report order.")
+	}
+
+	for _, expected := range []string{
+		"UnassociatedElasticIPAddresses  123456789011  us-east-1  2          1        1           $3.60    warning",
+		"RootAccountMissingMFA  123456789011  global  1          1        0           -        error",
+		"eipalloc-01",
+		"Estimated monthly savings: $3.60",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Table should contain %q, Got:\n%s", expected, out)
+		}
+	}
+
+	if strings.Contains(out, colorReset) {
+		t.Fatal("Table should not be colored when not writing to a terminal.")
+	}
+}
+
+func TestColorStatus(t *testing.T) {
+	if colorStatus("warning", true) != "\033[33mwarning"+colorReset {
+		t.Fatalf("Warning status should be yellow, Got %q", colorStatus("warning", true))
+	}
+
+	if colorStatus("warning", false) != "warning" {
+		t.Fatalf("Status should not be colored, Got %q", colorStatus("warning", false))
+	}
+}
+
+func TestWriteTable_tags(t *testing.T) {
+	results := newTestResults()
+	eips := results[1].(*common.StoredResult)
+	eips.Findings[0].Tags = map[string]string{"owner": "platform"}
+
+	var buf bytes.Buffer
+	if err := Write(FormatTable, &buf, results, Options{Tags: []string{"owner", "cost-center"}}); err != nil {
+		t.Fatalf("Unexpected error writing table: %s", err)
+	}
+	out := buf.String()
+
+	for _, expected := range []string{
+		"SAVINGS  OWNER     COST-CENTER  REASON",
+		"$3.60    platform  -            not associated",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Table should contain %q, Got:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultWorkers is the number of tasks run at once when Options.Workers is
+// not set.
+const DefaultWorkers = 10
+
+// Task is a unit of work run by the scheduler. The context passed to a task is
+// canceled once its timeout expires.
+type Task func(ctx context.Context) error
+
+// Options configure how tasks are run.
+type Options struct {
+	// Workers is the number of tasks run at once.
+	Workers int
+	// Timeout is the deadline of each task. Tasks without a timeout run until
+	// the parent context is done.
+	Timeout time.Duration
+}
+
+// Run runs every task with at most opts.Workers tasks at once and returns the
+// error of each task, in the order of tasks. A task that panics or exceeds its
+// timeout fails with an error while the other tasks keep running.
+func Run(ctx context.Context, tasks []Task, opts Options) []error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	errs := make([]error, len(tasks))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(tasks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = runTask(ctx, tasks[i], opts.Timeout)
+			}
+		}()
+	}
+	for i := range tasks {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return errs
+}
+
+func runTask(ctx context.Context, task Task, timeout time.Duration) (err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	if err := task(ctx); err != nil {
+		if timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s: %w", timeout, err)
+		}
+		return err
+	}
+	return nil
+}
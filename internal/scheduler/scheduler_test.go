@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	var running, maxRunning int
+	var tasks []Task
+	for i := 0; i < 20; i++ {
+		tasks = append(tasks, func(ctx context.Context) error {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	errs := Run(context.Background(), tasks, Options{Workers: 3})
+	if len(errs) != len(tasks) {
+		t.Fatalf("Run should return an error per task, Got %d", len(errs))
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Task %d should not fail, Got %s", i, err)
+		}
+	}
+	if maxRunning > 3 {
+		t.Fatalf("At most 3 tasks should run at once, Got %d", maxRunning)
+	}
+}
+
+func TestRun_isolatedFailures(t *testing.T) {
+	denied := errors.New("AccessDenied")
+	tasks := []Task{
+		func(ctx context.Context) error { return denied },
+		func(ctx context.Context) error { panic("nil map") },
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		func(ctx context.Context) error { return nil },
+	}
+
+	errs := Run(context.Background(), tasks, Options{Workers: 2, Timeout: 10 * time.Millisecond})
+	if !errors.Is(errs[0], denied) {
+		t.Fatalf("Task error should be returned, Got %v", errs[0])
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), "panic: nil map") {
+		t.Fatalf("Task panic should be returned as an error, Got %v", errs[1])
+	}
+	if !errors.Is(errs[2], context.DeadlineExceeded) || !strings.Contains(errs[2].Error(), "timed out after 10ms") {
+		t.Fatalf("Task exceeding its timeout should fail, Got %v", errs[2])
+	}
+	if errs[3] != nil {
+		t.Fatalf("Task should not be affected by other failing tasks, Got %v", errs[3])
+	}
+}
+
+func TestRun_noTasks(t *testing.T) {
+	if errs := Run(context.Background(), nil, Options{}); len(errs) != 0 {
+		t.Fatalf("Run without tasks should return no errors, Got %d", len(errs))
+	}
+}
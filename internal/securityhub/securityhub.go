@@ -0,0 +1,324 @@
+package securityhub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsSecurityHub "github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+const (
+	SchemaVersion = "2018-10-08"
+	ProductName   = "ckia"
+
+	// MaxBatchSize is the most findings BatchImportFindings accepts at once.
+	MaxBatchSize = 100
+
+	maxDescriptionLength    = 1024
+	maxRecommendationLength = 512
+)
+
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// Product identifies the Security Hub product findings are imported into. ckia
+// imports every finding into the default product of the account of the finding
+// in the region of the product, so findings of each account must be published
+// with credentials of that account.
+type Product struct {
+	Partition string
+	Region    string
+	AccountId string
+}
+
+// Arn returns the arn of the default product of the account.
+func (p Product) Arn() string {
+	partition := p.Partition
+	if partition == "" {
+		partition = "aws"
+	}
+	return fmt.Sprintf("arn:%s:securityhub:%s:%s:product/%s/default", partition, p.Region, p.AccountId, p.AccountId)
+}
+
+// NewClient returns a Security Hub client for cfg. When endpointURL is set it
+// replaces the Security Hub endpoint, e.g. to publish to a local stub.
+func NewClient(cfg aws.Config, endpointURL string) *awsSecurityHub.Client {
+	return awsSecurityHub.NewFromConfig(cfg, func(o *awsSecurityHub.Options) {
+		if endpointURL != "" {
+			o.EndpointResolver = awsSecurityHub.EndpointResolverFromURL(endpointURL)
+		}
+	})
+}
+
+// Findings converts every flagged and suppressed finding of results into AWS
+// Security Finding Format. Suppressed findings have a SUPPRESSED workflow
+// status so they are not lost when the resource is suppressed in ckia.
+func Findings(product Product, results []common.Result, now time.Time) []types.AwsSecurityFinding {
+	findings := []types.AwsSecurityFinding{}
+	for _, res := range results {
+		check := res.Metadata()
+		summary := res.Summary()
+		for _, finding := range summary.Findings {
+			findings = append(findings, expandSecurityFinding(product, check, finding, types.WorkflowStatusNew, now))
+		}
+		for _, suppressed := range summary.Suppressed {
+			findings = append(findings, expandSecurityFinding(product, check, suppressed.Finding, types.WorkflowStatusSuppressed, now))
+		}
+	}
+	return findings
+}
+
+// WriteFindings writes findings as the input of BatchImportFindings, which can
+// also be passed to `aws securityhub batch-import-findings --cli-input-json`.
+// Fields that are not set are omitted.
+func WriteFindings(w io.Writer, findings []types.AwsSecurityFinding) error {
+	content, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+	var document []interface{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{"Findings": prune(document)})
+}
+
+// ByAccount groups findings by the account they belong to, which they must be
+// published from.
+func ByAccount(findings []types.AwsSecurityFinding) map[string][]types.AwsSecurityFinding {
+	accounts := map[string][]types.AwsSecurityFinding{}
+	for _, finding := range findings {
+		accountId := aws.ToString(finding.AwsAccountId)
+		accounts[accountId] = append(accounts[accountId], finding)
+	}
+	return accounts
+}
+
+// BatchImportFindingsAPI is the Security Hub API used to publish findings.
+type BatchImportFindingsAPI interface {
+	BatchImportFindings(ctx context.Context, params *awsSecurityHub.BatchImportFindingsInput, optFns ...func(*awsSecurityHub.Options)) (*awsSecurityHub.BatchImportFindingsOutput, error)
+}
+
+// PublishResult is the outcome of publishing findings to Security Hub.
+type PublishResult struct {
+	SuccessCount int
+	FailedCount  int
+	Failed       []types.ImportFindingsError
+}
+
+// Publish imports findings into Security Hub in batches of MaxBatchSize. An
+// error is only returned when a batch could not be sent, findings rejected by
+// Security Hub are reported in the result.
+func Publish(ctx context.Context, api BatchImportFindingsAPI, findings []types.AwsSecurityFinding) (PublishResult, error) {
+	var result PublishResult
+	for start := 0; start < len(findings); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(findings) {
+			end = len(findings)
+		}
+
+		out, err := api.BatchImportFindings(ctx, &awsSecurityHub.BatchImportFindingsInput{
+			Findings: findings[start:end],
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.SuccessCount += int(out.SuccessCount)
+		result.FailedCount += int(out.FailedCount)
+		result.Failed = append(result.Failed, out.FailedFindings...)
+	}
+	return result, nil
+}
+
+func expandSecurityFinding(product Product, check common.Check, finding common.Finding, workflowStatus types.WorkflowStatus, now time.Time) types.AwsSecurityFinding {
+	timestamp := now.UTC().Format(time.RFC3339)
+	if finding.AccountId != "" {
+		product.AccountId = finding.AccountId
+	}
+	region := finding.Region
+	if region == "" {
+		region = product.Region
+	}
+
+	productFields := map[string]string{
+		"ckia/CheckId":  check.Id,
+		"ckia/Category": check.Category,
+	}
+	if finding.EstimatedMonthlySavings != 0 {
+		productFields["ckia/EstimatedMonthlySavings"] = fmt.Sprintf("%.2f", finding.EstimatedMonthlySavings)
+	}
+	for key, value := range finding.Metadata {
+		productFields["ckia/"+key] = value
+	}
+
+	remediation := &types.Remediation{Recommendation: &types.Recommendation{
+		Text: aws.String(truncate(check.RecommendedAction, maxRecommendationLength)),
+	}}
+	if url := urlPattern.FindString(check.AdditionalResources); url != "" {
+		remediation.Recommendation.Url = aws.String(url)
+	}
+
+	return types.AwsSecurityFinding{
+		SchemaVersion: aws.String(SchemaVersion),
+		Id:            aws.String(strings.Join([]string{ProductName, check.Id, finding.AccountId, region, finding.ResourceId}, "/")),
+		ProductArn:    aws.String(product.Arn()),
+		ProductName:   aws.String(ProductName),
+		GeneratorId:   aws.String(check.Id),
+		AwsAccountId:  aws.String(finding.AccountId),
+		Region:        aws.String(region),
+		Types:         []string{findingType(check.Category)},
+		CreatedAt:     aws.String(timestamp),
+		UpdatedAt:     aws.String(timestamp),
+		Severity: &types.Severity{
+			Label:    severityLabel(check.Severity),
+			Original: aws.String(check.Severity),
+		},
+		Title:         aws.String(check.Name),
+		Description:   aws.String(truncate(fmt.Sprintf("%s: %s. %s", finding.ResourceId, finding.Reason, check.Description), maxDescriptionLength)),
+		Remediation:   remediation,
+		ProductFields: productFields,
+		Resources:     []types.Resource{expandResource(product, finding, region)},
+		RecordState:   types.RecordStateActive,
+		Workflow:      &types.Workflow{Status: workflowStatus},
+	}
+}
+
+func expandResource(product Product, finding common.Finding, region string) types.Resource {
+	resource := types.Resource{
+		Type:      aws.String("Other"),
+		Id:        aws.String(finding.ResourceId),
+		Partition: types.Partition(product.Partition),
+		Region:    aws.String(region),
+		Tags:      finding.Tags,
+	}
+	if resource.Partition == "" {
+		resource.Partition = types.PartitionAws
+	}
+
+	resourceArn, err := arn.Parse(finding.ResourceArn)
+	if err != nil {
+		return resource
+	}
+	resource.Id = aws.String(finding.ResourceArn)
+	resource.Partition = types.Partition(resourceArn.Partition)
+	resource.Type = aws.String(resourceType(resourceArn))
+	return resource
+}
+
+// resourceType returns the Security Hub resource type of the resource arn, or
+// Other when the resource has no matching type.
+func resourceType(resourceArn arn.ARN) string {
+	resource := resourceArn.Resource
+	if i := strings.IndexAny(resource, "/:"); i >= 0 {
+		resource = resource[:i]
+	}
+	switch resourceArn.Service + ":" + resource {
+	case "ec2:instance":
+		return "AwsEc2Instance"
+	case "ec2:volume":
+		return "AwsEc2Volume"
+	case "ec2:elastic-ip":
+		return "AwsEc2Eip"
+	case "rds:db":
+		return "AwsRdsDbInstance"
+	case "elasticloadbalancing:loadbalancer":
+		return "AwsElbv2LoadBalancer"
+	case "iam:root":
+		return "AwsAccount"
+	}
+	return "Other"
+}
+
+func findingType(category string) string {
+	switch category {
+	case common.CategorySecurity:
+		return "Software and Configuration Checks/AWS Security Best Practices"
+	case common.CategoryCostOptimization:
+		return "Software and Configuration Checks/Cost Optimization"
+	case common.CategoryPerformance:
+		return "Software and Configuration Checks/Performance"
+	case common.CategoryFaultTolerance:
+		return "Software and Configuration Checks/Fault Tolerance"
+	case common.CategoryServiceLimits:
+		return "Software and Configuration Checks/Service Limits"
+	}
+	return "Software and Configuration Checks"
+}
+
+func severityLabel(severity string) types.SeverityLabel {
+	switch severity {
+	case common.SeverityCritical:
+		return types.SeverityLabelCritical
+	case common.SeverityHigh:
+		return types.SeverityLabelHigh
+	case common.SeverityMedium:
+		return types.SeverityLabelMedium
+	case common.SeverityLow:
+		return types.SeverityLabelLow
+	}
+	return types.SeverityLabelInformational
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-3]) + "..."
+}
+
+// prune removes null, zero and empty values from a decoded json document.
+func prune(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			child = prune(child)
+			if isEmpty(child) {
+				delete(v, key)
+				continue
+			}
+			v[key] = child
+		}
+		return v
+	case []interface{}:
+		pruned := []interface{}{}
+		for _, child := range v {
+			child = prune(child)
+			if !isEmpty(child) {
+				pruned = append(pruned, child)
+			}
+		}
+		return pruned
+	}
+	return value
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package suppression
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+// BaselineEntry identifies a finding recorded in a baseline.
+type BaselineEntry struct {
+	CheckId    string `json:"checkId"`
+	AccountId  string `json:"accountId"`
+	Region     string `json:"region,omitempty"`
+	ResourceId string `json:"resourceId"`
+}
+
+// Baseline is a snapshot of the findings of a previous run. Findings in the
+// baseline are suppressed so that only new findings are reported.
+type Baseline struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	Findings  []BaselineEntry `json:"findings"`
+
+	index map[BaselineEntry]bool
+}
+
+// NewBaseline records every finding of results, including findings that were
+// already suppressed by a baseline so that a baseline can be refreshed.
+func NewBaseline(results []common.Result, now time.Time) *Baseline {
+	baseline := &Baseline{CreatedAt: now.UTC(), Findings: []BaselineEntry{}}
+	for _, res := range results {
+		check := res.Metadata()
+		summary := res.Summary()
+		for _, finding := range summary.Findings {
+			baseline.add(baselineEntry(check, finding))
+		}
+		for _, suppressed := range summary.Suppressed {
+			if suppressed.SuppressedBy == common.SuppressedByBaseline {
+				baseline.add(baselineEntry(check, suppressed.Finding))
+			}
+		}
+	}
+	return baseline
+}
+
+// LoadBaseline reads the baseline file at path.
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, fmt.Errorf("unable to parse baseline file (%s): %w", path, err)
+	}
+	baseline.buildIndex()
+	return &baseline, nil
+}
+
+// Save writes the baseline to path.
+func (b *Baseline) Save(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// Contains returns true when the finding of the check is in the baseline.
+func (b *Baseline) Contains(check common.Check, finding common.Finding) bool {
+	if b.index == nil {
+		b.buildIndex()
+	}
+	return b.index[baselineEntry(check, finding)]
+}
+
+func (b *Baseline) add(entry BaselineEntry) {
+	if b.index == nil {
+		b.buildIndex()
+	}
+	if b.index[entry] {
+		return
+	}
+	b.index[entry] = true
+	b.Findings = append(b.Findings, entry)
+}
+
+func (b *Baseline) buildIndex() {
+	b.index = make(map[BaselineEntry]bool, len(b.Findings))
+	for _, entry := range b.Findings {
+		b.index[entry] = true
+	}
+}
+
+func baselineEntry(check common.Check, finding common.Finding) BaselineEntry {
+	return BaselineEntry{
+		CheckId:    check.Id,
+		AccountId:  finding.AccountId,
+		Region:     finding.Region,
+		ResourceId: finding.ResourceId,
+	}
+}
@@ -0,0 +1,56 @@
+package suppression
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func TestBaselineRoundTrip(t *testing.T) {
+	now := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	res := newTestResult(
+		common.Finding{ResourceId: "eipalloc-01", AccountId: "123456789011", Region: "us-east-1"},
+		common.Finding{ResourceId: "eipalloc-02", AccountId: "123456789011", Region: "us-east-1"},
+	)
+
+	baseline := NewBaseline([]common.Result{res}, now)
+	if len(baseline.Findings) != 2 {
+		t.Fatalf("Baseline should contain 2 findings, Got %d", len(baseline.Findings))
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := baseline.Save(path); err != nil {
+		t.Fatalf("Unexpected error saving baseline: %s", err)
+	}
+
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading baseline: %s", err)
+	}
+
+	if !loaded.CreatedAt.Equal(now) {
+		t.Fatalf("CreatedAt should be %s, Got %s", now, loaded.CreatedAt)
+	}
+
+	if !loaded.Contains(res.Check, common.Finding{ResourceId: "eipalloc-02", AccountId: "123456789011", Region: "us-east-1"}) {
+		t.Fatal("Baseline should contain eipalloc-02.")
+	}
+
+	if loaded.Contains(res.Check, common.Finding{ResourceId: "eipalloc-02", AccountId: "123456789011", Region: "us-west-2"}) {
+		t.Fatal("Baseline should not contain eipalloc-02 in another region.")
+	}
+}
+
+func TestNewBaseline_keepsBaselinedFindings(t *testing.T) {
+	res := newTestResult(common.Finding{ResourceId: "eipalloc-01", AccountId: "123456789011", Region: "us-east-1"})
+	Suppressor{Baseline: &Baseline{Findings: []BaselineEntry{
+		{CheckId: res.Id, AccountId: "123456789011", Region: "us-east-1", ResourceId: "eipalloc-01"},
+	}}}.Apply(res)
+
+	baseline := NewBaseline([]common.Result{res}, time.Now())
+	if len(baseline.Findings) != 1 {
+		t.Fatalf("Baseline should keep findings suppressed by a previous baseline, Got %d", len(baseline.Findings))
+	}
+}
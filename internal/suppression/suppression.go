@@ -0,0 +1,156 @@
+package suppression
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+	"gopkg.in/yaml.v3"
+)
+
+// DateFormat is the format of the expires field of a rule.
+const DateFormat = "2006-01-02"
+
+// Rule suppresses the findings it matches. Every selector that is set must
+// match the finding, and at least one selector must be set.
+type Rule struct {
+	// CheckId is the full id or short name of a check, e.g. IdleDBInstances.
+	CheckId string `yaml:"checkId"`
+	// Resource is matched against the resource id and the resource arn.
+	Resource string `yaml:"resource"`
+	// Tag is either a tag key or a key=value pair.
+	Tag    string `yaml:"tag"`
+	Region string `yaml:"region"`
+	// Expires is the last day, in YYYY-MM-DD format, the rule applies.
+	Expires string `yaml:"expires"`
+	Reason  string `yaml:"reason"`
+
+	expires time.Time
+}
+
+// File is the content of a suppression file.
+type File struct {
+	Suppressions []Rule `yaml:"suppressions"`
+}
+
+// Load reads and validates the suppression file at path.
+func Load(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse suppression file (%s): %w", path, err)
+	}
+
+	for i := range file.Suppressions {
+		if err := file.Suppressions[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid suppression (%d) in (%s): %w", i+1, path, err)
+		}
+	}
+	return &file, nil
+}
+
+func (r *Rule) validate() error {
+	if r.CheckId == "" && r.Resource == "" && r.Tag == "" && r.Region == "" {
+		return errors.New("at least one of checkId, resource, tag or region is required")
+	}
+	if r.Expires != "" {
+		expires, err := time.Parse(DateFormat, r.Expires)
+		if err != nil {
+			return fmt.Errorf("expires must be a date in YYYY-MM-DD format: %w", err)
+		}
+		r.expires = expires
+	}
+	return nil
+}
+
+// Expired returns true when the rule has an expiry date before the day of now.
+func (r Rule) Expired(now time.Time) bool {
+	if r.expires.IsZero() {
+		return false
+	}
+	return now.UTC().After(r.expires.AddDate(0, 0, 1))
+}
+
+// Matches returns true when every selector of the rule matches the finding of
+// the check.
+func (r Rule) Matches(check common.Check, finding common.Finding) bool {
+	if r.CheckId != "" && r.CheckId != check.Id && !strings.EqualFold(r.CheckId, check.ShortName()) {
+		return false
+	}
+	if r.Resource != "" && r.Resource != finding.ResourceId && r.Resource != finding.ResourceArn {
+		return false
+	}
+	if r.Region != "" && r.Region != finding.Region {
+		return false
+	}
+	if r.Tag != "" {
+		key, value, hasValue := strings.Cut(r.Tag, "=")
+		tagValue, ok := finding.Tags[key]
+		if !ok || (hasValue && tagValue != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Unmatchable returns true when the rule selects a tag but only targets a
+// check whose findings have no tags, so it can never match.
+func (r Rule) Unmatchable(check common.Check) bool {
+	if r.Tag == "" || r.CheckId == "" || !check.Untagged {
+		return false
+	}
+	return r.CheckId == check.Id || strings.EqualFold(r.CheckId, check.ShortName())
+}
+
+// Expired returns the rules of the file that have expired.
+func (f *File) Expired(now time.Time) []Rule {
+	var expired []Rule
+	for _, rule := range f.Suppressions {
+		if rule.Expired(now) {
+			expired = append(expired, rule)
+		}
+	}
+	return expired
+}
+
+// Match returns the first rule that has not expired and matches the finding.
+func (f *File) Match(check common.Check, finding common.Finding, now time.Time) (Rule, bool) {
+	for _, rule := range f.Suppressions {
+		if !rule.Expired(now) && rule.Matches(check, finding) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Suppressor applies suppression rules and a baseline to check results. Either
+// may be nil.
+type Suppressor struct {
+	Rules    *File
+	Baseline *Baseline
+	Now      time.Time
+}
+
+// Apply moves the findings of res that match a suppression rule or are in the
+// baseline into its suppressed findings.
+func (s Suppressor) Apply(res common.Result) {
+	check := res.Metadata()
+	res.Suppress(check.Severity, func(finding common.Finding) (common.SuppressedFinding, bool) {
+		if s.Rules != nil {
+			if rule, ok := s.Rules.Match(check, finding, s.Now); ok {
+				return common.SuppressedFinding{Finding: finding, SuppressedBy: common.SuppressedBySuppression, SuppressionReason: rule.Reason}, true
+			}
+		}
+		if s.Baseline != nil && s.Baseline.Contains(check, finding) {
+			return common.SuppressedFinding{Finding: finding, SuppressedBy: common.SuppressedByBaseline}, true
+		}
+		return common.SuppressedFinding{}, false
+	})
+}
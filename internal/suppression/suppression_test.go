@@ -0,0 +1,160 @@
+package suppression
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brittandeyoung/ckia/internal/common"
+)
+
+func newTestResult(findings ...common.Finding) *common.StoredResult {
+	res := &common.StoredResult{
+		Check:       common.Check{Id: "ckia:aws:cost:UnassociatedElasticIPAddresses", Severity: common.SeverityLow},
+		CheckResult: common.NewCheckResult("123456789011", "us-east-1", nil),
+	}
+	res.ResourcesEvaluated = len(findings)
+	for _, finding := range findings {
+		res.AddFinding(finding)
+	}
+	res.Evaluate(res.Severity)
+	return res
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.yaml")
+	content := `suppressions:
+  - checkId: UnassociatedElasticIPAddresses
+    resource: eipalloc-01
+    expires: 2023-06-30
+    reason: partner allowlist
+  - tag: environment=standby
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading suppressions: %s", err)
+	}
+
+	if len(file.Suppressions) != 2 {
+		t.Fatalf("Suppressions should contain 2 rules, Got %d", len(file.Suppressions))
+	}
+
+	if file.Suppressions[0].Expired(time.Date(2023, 6, 30, 23, 0, 0, 0, time.UTC)) {
+		t.Fatal("Rule should not be expired on its expiry date.")
+	}
+
+	if !file.Suppressions[0].Expired(time.Date(2023, 7, 1, 1, 0, 0, 0, time.UTC)) {
+		t.Fatal("Rule should be expired after its expiry date.")
+	}
+}
+
+func TestLoad_invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no selector": "suppressions:\n  - reason: no selector\n",
+		"bad expires": "suppressions:\n  - region: us-east-1\n    expires: 30/06/2023\n",
+	} {
+		path := filepath.Join(t.TempDir(), "suppressions.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Load(path); err == nil {
+			t.Fatalf("Expected an error for a suppression with %s.", name)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	check := common.Check{Id: "ckia:aws:cost:IdleDBInstances"}
+	finding := common.Finding{
+		ResourceId:  "standby-db",
+		ResourceArn: "arn:aws:rds:us-east-1:123456789011:db:standby-db",
+		Region:      "us-east-1",
+		Tags:        map[string]string{"environment": "standby"},
+	}
+
+	cases := map[string]struct {
+		rule    Rule
+		matches bool
+	}{
+		"check id":        {Rule{CheckId: "ckia:aws:cost:IdleDBInstances"}, true},
+		"short name":      {Rule{CheckId: "idledbinstances"}, true},
+		"other check":     {Rule{CheckId: "IdleLoadBalancers"}, false},
+		"resource id":     {Rule{Resource: "standby-db"}, true},
+		"resource arn":    {Rule{Resource: "arn:aws:rds:us-east-1:123456789011:db:standby-db"}, true},
+		"tag key":         {Rule{Tag: "environment"}, true},
+		"tag key value":   {Rule{Tag: "environment=standby"}, true},
+		"other tag value": {Rule{Tag: "environment=production"}, false},
+		"region":          {Rule{Region: "us-east-1"}, true},
+		"other region":    {Rule{CheckId: "IdleDBInstances", Region: "us-west-2"}, false},
+	}
+
+	for name, c := range cases {
+		if c.rule.Matches(check, finding) != c.matches {
+			t.Fatalf("Rule (%s) should match: %t", name, c.matches)
+		}
+	}
+}
+
+func TestRuleUnmatchable(t *testing.T) {
+	untagged := common.Check{Id: "ckia:aws:security:RootAccountMissingMFA", Untagged: true}
+	tagged := common.Check{Id: "ckia:aws:cost:IdleLoadBalancers"}
+
+	cases := map[string]struct {
+		rule        Rule
+		check       common.Check
+		unmatchable bool
+	}{
+		"tag of untagged check":    {Rule{CheckId: "RootAccountMissingMFA", Tag: "owner"}, untagged, true},
+		"tag of tagged check":      {Rule{CheckId: "IdleLoadBalancers", Tag: "owner"}, tagged, false},
+		"tag of any check":         {Rule{Tag: "owner"}, untagged, false},
+		"resource of untagged":     {Rule{CheckId: "RootAccountMissingMFA", Resource: "123456789011"}, untagged, false},
+		"tag of other check":       {Rule{CheckId: "IdleLoadBalancers", Tag: "owner"}, untagged, false},
+		"tag of untagged check id": {Rule{CheckId: "ckia:aws:security:RootAccountMissingMFA", Tag: "owner"}, untagged, true},
+	}
+
+	for name, c := range cases {
+		if c.rule.Unmatchable(c.check) != c.unmatchable {
+			t.Fatalf("Rule (%s) should be unmatchable: %t", name, c.unmatchable)
+		}
+	}
+}
+
+func TestSuppressorApply(t *testing.T) {
+	now := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	res := newTestResult(
+		common.Finding{ResourceId: "eipalloc-01", AccountId: "123456789011", Region: "us-east-1"},
+		common.Finding{ResourceId: "eipalloc-02", AccountId: "123456789011", Region: "us-east-1"},
+		common.Finding{ResourceId: "eipalloc-03", AccountId: "123456789011", Region: "us-east-1"},
+	)
+	rules := &File{Suppressions: []Rule{
+		{Resource: "eipalloc-01", Reason: "partner allowlist"},
+		{Resource: "eipalloc-03", Reason: "expired", expires: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	baseline := &Baseline{Findings: []BaselineEntry{
+		{CheckId: res.Id, AccountId: "123456789011", Region: "us-east-1", ResourceId: "eipalloc-02"},
+	}}
+
+	Suppressor{Rules: rules, Baseline: baseline, Now: now}.Apply(res)
+
+	if res.ResourcesFlagged != 1 || res.Findings[0].ResourceId != "eipalloc-03" {
+		t.Fatalf("Only eipalloc-03 should be flagged, Got %v", res.Findings)
+	}
+
+	if len(res.Suppressed) != 2 {
+		t.Fatalf("Suppressed should contain 2 findings, Got %d", len(res.Suppressed))
+	}
+
+	if res.Suppressed[0].SuppressedBy != common.SuppressedBySuppression || res.Suppressed[0].SuppressionReason != "partner allowlist" {
+		t.Fatalf("eipalloc-01 should be suppressed by a suppression rule, Got %v", res.Suppressed[0])
+	}
+
+	if res.Suppressed[1].SuppressedBy != common.SuppressedByBaseline {
+		t.Fatalf("eipalloc-02 should be suppressed by the baseline, Got %v", res.Suppressed[1])
+	}
+}